@@ -8,11 +8,13 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 
 	"github.com/bavarianbidi/kubectl-dpm/pkg/command"
 	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
 )
 
 func main() {
@@ -25,9 +27,17 @@ func run() error {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer stop()
 
+	// git sources share their clones for the whole run
+	defer profile.CleanupGitClones()
+
 	flags := pflag.NewFlagSet("kubectl-dpm", pflag.ExitOnError)
 	pflag.CommandLine = flags
 
+	return newRoot().ExecuteContext(ctx)
+}
+
+// newRoot creates the root command with all sub commands
+func newRoot() *cobra.Command {
 	// create root command
 	root := command.Root()
 
@@ -49,8 +59,26 @@ func run() error {
 	root.AddCommand(command.ValidateDebugProfileFile())
 	// list sub command
 	root.AddCommand(command.List())
+	// init sub command
+	root.AddCommand(command.Init())
+	// profile sub command
+	root.AddCommand(command.Profile())
+	// sessions sub command
+	root.AddCommand(command.Sessions(
+		genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr},
+	))
+	// collect sub command
+	root.AddCommand(command.Collect())
+	// audit sub command
+	root.AddCommand(command.Audit())
+	// auth sub command
+	root.AddCommand(command.Auth())
+	// policy sub command
+	root.AddCommand(command.Policy())
+	// config sub command
+	root.AddCommand(command.Config())
 	// version sub command
 	root.AddCommand(command.Version())
 
-	return root.ExecuteContext(ctx)
+	return root
 }
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// TestHelp runs --help for every command, flags of a sub command clashing with the
+// persistent flags of the root command only panic once the command is executed
+func TestHelp(t *testing.T) {
+	var paths [][]string
+
+	var walk func(cmd *cobra.Command, path []string)
+	walk = func(cmd *cobra.Command, path []string) {
+		paths = append(paths, path)
+		for _, sub := range cmd.Commands() {
+			walk(sub, append(path[:len(path):len(path)], sub.Name()))
+		}
+	}
+	walk(newRoot(), nil)
+
+	for _, path := range paths {
+		t.Run(strings.Join(append([]string{"kubectl-dpm"}, path...), " "), func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("--help panicked: %v", r)
+				}
+			}()
+
+			// every run gets a new command tree, as flags are parsed into package variables
+			root := newRoot()
+			root.SetArgs(append(path, "--help"))
+
+			var out strings.Builder
+			root.SetOut(&out)
+			root.SetErr(&out)
+
+			if err := root.Execute(); err != nil {
+				t.Fatalf("--help error = %v", err)
+			}
+			if !strings.Contains(out.String(), "Usage:") {
+				t.Errorf("--help printed no usage:\n%s", out.String())
+			}
+		})
+	}
+}
+
+// TestFlagShadowing checks that no command defines a flag which is already a persistent
+// flag of a parent command, e.g. --cluster of the kubeconfig flags
+func TestFlagShadowing(t *testing.T) {
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+			for parent := cmd.Parent(); parent != nil; parent = parent.Parent() {
+				if parent.PersistentFlags().Lookup(f.Name) != nil {
+					t.Errorf("%s: flag --%s shadows the persistent flag of %s", cmd.CommandPath(), f.Name, parent.CommandPath())
+				}
+			}
+		})
+
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(newRoot())
+}
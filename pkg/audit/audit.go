@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: MIT
+
+// Package audit records debug sessions as JSON lines in a local audit log.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Entry is a single debug session
+type Entry struct {
+	Timestamp       time.Time `json:"timestamp"`
+	Context         string    `json:"context"`
+	Cluster         string    `json:"cluster,omitempty"`
+	User            string    `json:"user"`
+	Profile         string    `json:"profile"`
+	SourceType      string    `json:"sourceType"`
+	SpecDigest      string    `json:"specDigest,omitempty"`
+	Namespace       string    `json:"namespace"`
+	Pod             string    `json:"pod"`
+	TargetContainer string    `json:"targetContainer,omitempty"`
+	Container       string    `json:"container,omitempty"` // name of the ephemeral debug container
+	Image           string    `json:"image"`
+	ExitCode        int       `json:"exitCode"`
+	// Duration is the duration of the kubectl debug session in seconds
+	Duration float64 `json:"duration"`
+	// Denied is set if the policy denied the debug session, Reason is the reason of the policy
+	Denied bool   `json:"denied,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// Error is set if kubectl debug couldn't be started or failed
+	Error string `json:"error,omitempty"`
+	// Recording is the file of the recording of the debug session
+	Recording string `json:"recording,omitempty"`
+	// Artifacts is the directory of the files collected from the debug container
+	Artifacts string `json:"artifacts,omitempty"`
+}
+
+// DefaultPath returns the default location of the audit log
+func DefaultPath() string {
+	return os.Getenv("HOME") + "/.kube-dpm/audit.log"
+}
+
+// Append appends the entry as a single JSON line to the audit log
+func Append(file string, e *Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal audit entry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return fmt.Errorf("create audit log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+
+	return f.Close()
+}
+
+// Filter selects audit entries, empty fields match every entry
+type Filter struct {
+	Profile string // profile name or shell pattern
+	Pod     string // pod name or shell pattern
+	Since   time.Time
+	Until   time.Time
+}
+
+// Match reports whether the entry matches the filter
+func (f *Filter) Match(e *Entry) bool {
+	return matches(f.Profile, e.Profile) &&
+		matches(f.Pod, e.Pod) &&
+		(f.Since.IsZero() || !e.Timestamp.Before(f.Since)) &&
+		(f.Until.IsZero() || !e.Timestamp.After(f.Until))
+}
+
+func matches(pattern, name string) bool {
+	if pattern == "" || pattern == name {
+		return true
+	}
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
+
+// Read returns all entries of the audit log matching the filter in the order they were recorded.
+// A missing audit log has no entries.
+func Read(file string, filter *Filter) ([]Entry, error) {
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("parse audit log %s:%d: %w", file, line, err)
+		}
+
+		if filter.Match(&e) {
+			entries = append(entries, e)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+
+	return entries, nil
+}
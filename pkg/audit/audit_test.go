@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "logs", "audit.log")
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	entries := []Entry{
+		{Timestamp: start, Profile: "webapp", Pod: "webapp-7d9c6b5f4-x2x9q", Namespace: "default", ExitCode: 0, Duration: 42.5},
+		{Timestamp: start.Add(time.Hour), Profile: "netadmin", Pod: "db-0", Denied: true, Reason: "NET_ADMIN is not allowed"},
+		{Timestamp: start.Add(2 * time.Hour), Profile: "webapp", Pod: "webapp-7d9c6b5f4-zt8mq", ExitCode: 130},
+	}
+
+	// a missing audit log has no entries
+	got, err := Read(file, &Filter{})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("Read() of missing audit log = %v, %v, want no entries", got, err)
+	}
+
+	for i := range entries {
+		if err := Append(file, &entries[i]); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("audit log permissions = %v, want 0600", info.Mode().Perm())
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int // indexes of entries
+	}{
+		{name: "all", want: []int{0, 1, 2}},
+		{name: "profile", filter: Filter{Profile: "webapp"}, want: []int{0, 2}},
+		{name: "pod pattern", filter: Filter{Pod: "webapp-*-zt8mq"}, want: []int{2}},
+		{name: "since", filter: Filter{Since: start.Add(time.Hour)}, want: []int{1, 2}},
+		{name: "until", filter: Filter{Until: start.Add(30 * time.Minute)}, want: []int{0}},
+		{name: "time range and profile", filter: Filter{Profile: "webapp", Since: start.Add(time.Minute), Until: start.Add(3 * time.Hour)}, want: []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(file, &tt.filter)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Read() = %d entries, want %d", len(got), len(tt.want))
+			}
+			for i, idx := range tt.want {
+				want := entries[idx]
+				if !got[i].Timestamp.Equal(want.Timestamp) || got[i].Pod != want.Pod || got[i].Denied != want.Denied ||
+					got[i].ExitCode != want.ExitCode || got[i].Reason != want.Reason {
+					t.Errorf("Read()[%d] = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.log")
+	if err := os.WriteFile(file, []byte("{\"profile\":\"webapp\"}\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Read(file, &Filter{})
+	if err == nil || !strings.Contains(err.Error(), "audit.log:2:") {
+		t.Errorf("Read() error = %v, want the line of the invalid entry", err)
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: MIT
+
+// Package collect copies files out of a container as a tar stream, like kubectl cp.
+package collect
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// TarCommand returns the command which writes the paths as tar stream to stdout,
+// the paths are relative to / in the stream
+func TarCommand(paths []string) []string {
+	cmd := []string{"tar", "cf", "-", "-C", "/"}
+	for _, p := range paths {
+		cmd = append(cmd, strings.TrimPrefix(path.Clean("/"+p), "/"))
+	}
+	return cmd
+}
+
+// Extract extracts the regular files and directories of the tar stream into dir and returns
+// the extracted files. All entries are kept within dir, links and special files are skipped.
+func Extract(r io.Reader, dir string) ([]string, error) {
+	var files []string
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return files, fmt.Errorf("read tar stream: %w", err)
+		}
+
+		name := path.Clean("/" + hdr.Name)[1:]
+		if name == "" {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o700); err != nil {
+				return files, fmt.Errorf("create directory %s: %w", target, err)
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, target, hdr.FileInfo().Mode().Perm()); err != nil {
+				return files, err
+			}
+			files = append(files, name)
+		}
+	}
+}
+
+func extractFile(r io.Reader, target string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return fmt.Errorf("create directory of %s: %w", target, err)
+	}
+
+	// files are only readable by the user, they may contain secrets
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode&0o700|0o600)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", target, err)
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", target, err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: MIT
+
+package collect
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTarCommand(t *testing.T) {
+	got := TarCommand([]string{"/tmp/capture.pcap", "/var/log/../dumps/", "relative"})
+	want := []string{"tar", "cf", "-", "-C", "/", "tmp/capture.pcap", "var/dumps", "relative"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TarCommand() = %v, want %v", got, want)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	entries := []struct {
+		hdr  tar.Header
+		data string
+	}{
+		{hdr: tar.Header{Name: "tmp/", Typeflag: tar.TypeDir, Mode: 0o755}},
+		{hdr: tar.Header{Name: "tmp/capture.pcap", Typeflag: tar.TypeReg, Mode: 0o644}, data: "pcap"},
+		{hdr: tar.Header{Name: "../../escape", Typeflag: tar.TypeReg, Mode: 0o644}, data: "escape"},
+		{hdr: tar.Header{Name: "tmp/passwd", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+	}
+	for _, e := range entries {
+		e.hdr.Size = int64(len(e.data))
+		if err := tw.WriteHeader(&e.hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files, err := Extract(&buf, dir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	if want := []string{"tmp/capture.pcap", "escape"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Extract() = %v, want %v", files, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "tmp", "capture.pcap"))
+	if err != nil || string(data) != "pcap" {
+		t.Errorf("extracted file = %q, %v", data, err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "tmp", "capture.pcap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("extracted file mode = %o, want 600", mode)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, "tmp", "passwd")); !os.IsNotExist(err) {
+		t.Errorf("symlink got extracted: %v", err)
+	}
+}
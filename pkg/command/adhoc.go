@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+// selectAdHocProfile adds the profile of --spec or --source to the configuration and selects it.
+// The ad-hoc profile targets the namespace of the kubeconfig (or --namespace).
+func selectAdHocProfile() error {
+	ref := flagAdHocSource
+	if flagAdHocSpec != "" {
+		ref = profile.SourceTypeFile + ":" + flagAdHocSpec
+	}
+
+	source, err := profile.ParseSourceRef(ref)
+	if err != nil {
+		return err
+	}
+
+	if flagImage == "" {
+		return fmt.Errorf("--image is required with --%s and --%s", specFlagName, sourceFlagName)
+	}
+
+	namespace, _, err := MatchVersionKubeConfigFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return fmt.Errorf("get namespace of kubeconfig: %w", err)
+	}
+
+	if err := profile.AddAdHocProfile(profile.Profile{
+		ProfileName:   profile.AdHocProfileName,
+		ProfileSource: source,
+		Image:         flagImage,
+		Namespace:     namespace,
+	}); err != nil {
+		return err
+	}
+
+	flagProfileName = profile.AdHocProfileName
+
+	return nil
+}
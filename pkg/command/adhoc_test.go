@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func TestSelectAdHocProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       string
+		source     string
+		image      string
+		wantSource profile.ProfileSourceConfig
+		wantErr    bool
+	}{
+		{
+			name:       "spec file",
+			spec:       "./my.json",
+			image:      "busybox",
+			wantSource: profile.ProfileSourceConfig{Type: profile.SourceTypeFile, Path: "./my.json"},
+		},
+		{
+			name:       "builtin source",
+			source:     "builtin:netadmin",
+			image:      "busybox",
+			wantSource: profile.ProfileSourceConfig{Type: profile.SourceTypeBuiltIn, Name: "netadmin"},
+		},
+		{name: "missing image", spec: "./my.json", wantErr: true},
+		{name: "invalid source", source: "git+https://github.com/org/profiles", image: "busybox", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagAdHocSpec, flagAdHocSource, flagImage = tt.spec, tt.source, tt.image
+			profile.Config = profile.CustomDebugProfile{}
+			t.Cleanup(func() {
+				flagAdHocSpec, flagAdHocSource, flagImage, flagProfileName = "", "", "", ""
+				profile.Config = profile.CustomDebugProfile{}
+			})
+
+			namespace := "staging"
+			kubeConfigFlags := genericclioptions.NewConfigFlags(true)
+			kubeConfigFlags.Namespace = &namespace
+			MatchVersionKubeConfigFlags = cmdutil.NewMatchVersionFlags(kubeConfigFlags)
+
+			err := selectAdHocProfile()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectAdHocProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if flagProfileName != profile.AdHocProfileName {
+				t.Errorf("flagProfileName = %q, want %q", flagProfileName, profile.AdHocProfileName)
+			}
+
+			p := profile.Config.Profiles[0]
+			if p.ProfileSource.Type != tt.wantSource.Type || p.ProfileSource.Path != tt.wantSource.Path || p.ProfileSource.Name != tt.wantSource.Name {
+				t.Errorf("ProfileSource = %+v, want %+v", p.ProfileSource, tt.wantSource)
+			}
+			if p.Image != tt.image || p.Namespace != namespace {
+				t.Errorf("Image = %q, Namespace = %q, want %q and %q", p.Image, p.Namespace, tt.image, namespace)
+			}
+		})
+	}
+}
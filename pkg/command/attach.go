@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/kubectl/pkg/util/term"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/recording"
+)
+
+// sessionStartTimeout is the time the ephemeral container of a debug session has to start
+const sessionStartTimeout = 2 * time.Minute
+
+// attachSession attaches to the ephemeral container of a debug session created by
+// kubectl debug --attach=false, records it to recordFile if set and enforces the limits
+func attachSession(ctx context.Context, namespace, podName, container, recordFile string, limits profile.SessionLimits, streams genericiooptions.IOStreams) error {
+	clientset, err := newClientset()
+	if err != nil {
+		return err
+	}
+
+	podName = strings.TrimPrefix(podName, "pod/")
+	if err := waitForSessionContainer(ctx, clientset, namespace, podName, container); err != nil {
+		return err
+	}
+
+	opts := &attachOptions{}
+
+	if recordFile != "" {
+		rec, f, err := newSessionRecorder(recordFile, namespace, podName, streams)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		defer func() {
+			if err := rec.Err(); err != nil {
+				fmt.Fprintf(streams.ErrOut, "warning: write recording %s: %v\n", recordFile, err)
+			}
+		}()
+
+		opts.rec = rec
+	}
+
+	if limits.Active() {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		watchdog := newSessionWatchdog(limits, time.Now)
+		opts.onInput = watchdog.touch
+		go watchdog.run(ctx, cancel, streams.ErrOut)
+	}
+
+	err = attachContainer(ctx, namespace, podName, container, true, streams, opts)
+
+	var limitErr *sessionLimitError
+	if errors.As(context.Cause(ctx), &limitErr) {
+		fmt.Fprintf(streams.ErrOut, "debug session ended: %v\n", limitErr)
+		if limits.Kill {
+			killSession(ctx, namespace, podName, container, streams)
+		}
+		return limitErr
+	}
+
+	return err
+}
+
+// waitForSessionContainer waits until the ephemeral container is running
+func waitForSessionContainer(ctx context.Context, client kubernetes.Interface, namespace, podName, container string) error {
+	ctx, cancel := context.WithTimeout(ctx, sessionStartTimeout)
+	defer cancel()
+
+	_, err := waitForContainerState(ctx, client, namespace, podName, container, func(state *corev1.ContainerState) (bool, error) {
+		if state.Terminated != nil {
+			return false, fmt.Errorf("ephemeral container %s terminated: %s", container, state.Terminated.Reason)
+		}
+		return state.Running != nil, nil
+	})
+
+	return err
+}
+
+// waitForContainerState polls the state of the ephemeral container until done returns true
+func waitForContainerState(ctx context.Context, client kubernetes.Interface, namespace, podName, container string,
+	done func(state *corev1.ContainerState) (bool, error),
+) (*corev1.ContainerState, error) {
+	var state *corev1.ContainerState
+
+	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name == container {
+				state = &status.State
+				return done(state)
+			}
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wait for ephemeral container %s in pod %s: %w", container, podName, err)
+	}
+
+	return state, nil
+}
+
+// attachContainer attaches the streams to a running container like kubectl attach -it.
+// A TTY is only used if the container has one and the input is a terminal.
+// The options are optional.
+func attachContainer(ctx context.Context, namespace, podName, container string, tty bool, streams genericiooptions.IOStreams, opts *attachOptions) error {
+	restConfig, err := MatchVersionKubeConfigFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("get REST config: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("create k8s clientset: %w", err)
+	}
+
+	t := term.TTY{In: streams.In, Out: streams.Out, Raw: tty}
+	tty = tty && t.IsTerminalIn()
+
+	// the terminal is set up with the original streams, only the copied data is wrapped
+	var rec *recording.Recorder
+	if opts != nil {
+		rec = opts.rec
+		streams = opts.wrap(streams, tty)
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("attach").
+		VersionedParams(&corev1.PodAttachOptions{
+			Container: container,
+			Stdin:     streams.In != nil,
+			Stdout:    streams.Out != nil,
+			Stderr:    !tty,
+			TTY:       tty,
+		}, scheme.ParameterCodec)
+
+	executor, err := newExecutor(restConfig, req.URL())
+	if err != nil {
+		return err
+	}
+
+	stream := func() error {
+		options := remotecommand.StreamOptions{
+			Stdin:  streams.In,
+			Stdout: streams.Out,
+			Tty:    tty,
+		}
+		if tty {
+			if sizeQueue := t.MonitorSize(t.GetSize()); sizeQueue != nil {
+				options.TerminalSizeQueue = &terminalSizeQueue{delegate: sizeQueue, rec: rec}
+			}
+		} else {
+			options.Stderr = streams.ErrOut
+		}
+		return executor.StreamWithContext(ctx, options)
+	}
+
+	if !tty {
+		return stream()
+	}
+
+	return t.Safe(stream)
+}
+
+// attachOptions are the optional features of an attached debug session
+type attachOptions struct {
+	// rec records the session
+	rec *recording.Recorder
+	// onInput is called on every input
+	onInput func()
+}
+
+func (o *attachOptions) wrap(streams genericiooptions.IOStreams, tty bool) genericiooptions.IOStreams {
+	if o.onInput != nil && streams.In != nil {
+		streams.In = &inputNotifier{r: streams.In, notify: o.onInput}
+	}
+
+	if o.rec != nil {
+		streams.In = o.rec.Input(streams.In)
+		streams.Out = o.rec.Output(streams.Out)
+		if !tty {
+			streams.ErrOut = o.rec.Output(streams.ErrOut)
+		}
+	}
+
+	return streams
+}
+
+type inputNotifier struct {
+	r      io.Reader
+	notify func()
+}
+
+func (n *inputNotifier) Read(p []byte) (int, error) {
+	c, err := n.r.Read(p)
+	if c > 0 {
+		n.notify()
+	}
+	return c, err
+}
+
+// execInContainer runs the command in the container without stdin and TTY
+func execInContainer(ctx context.Context, namespace, podName, container string, command []string, stdout, stderr io.Writer) error {
+	restConfig, err := MatchVersionKubeConfigFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("get REST config: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("create k8s clientset: %w", err)
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := newExecutor(restConfig, req.URL())
+	if err != nil {
+		return err
+	}
+
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: stdout, Stderr: stderr})
+}
+
+// newExecutor prefers websockets like kubectl and falls back to SPDY for older API servers
+func newExecutor(restConfig *rest.Config, u *url.URL) (remotecommand.Executor, error) {
+	websocketExec, err := remotecommand.NewWebSocketExecutor(restConfig, "GET", u.String())
+	if err != nil {
+		return nil, fmt.Errorf("create websocket executor: %w", err)
+	}
+	spdyExec, err := remotecommand.NewSPDYExecutor(restConfig, "POST", u)
+	if err != nil {
+		return nil, fmt.Errorf("create SPDY executor: %w", err)
+	}
+	executor, err := remotecommand.NewFallbackExecutor(websocketExec, spdyExec, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create executor: %w", err)
+	}
+	return executor, nil
+}
+
+// terminalSizeQueue adapts the terminal size queue of kubectl to remotecommand
+// and records the resizes
+type terminalSizeQueue struct {
+	delegate term.TerminalSizeQueue
+	rec      *recording.Recorder
+}
+
+func (q *terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	size := q.delegate.Next()
+	if size == nil {
+		return nil
+	}
+	if q.rec != nil {
+		q.rec.Resize(int(size.Width), int(size.Height))
+	}
+	return &remotecommand.TerminalSize{Width: size.Width, Height: size.Height}
+}
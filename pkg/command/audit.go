@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/audit"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func Audit() *cobra.Command {
+	var (
+		filter     audit.Filter
+		flagSince  string
+		flagUntil  string
+		flagOutput string
+	)
+
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "show the recorded debug sessions",
+		Long: "show the debug sessions recorded in the audit log. Every run appends an entry, " +
+			"including runs denied by the policy.",
+		Example: `  kubectl dpm audit --profile webapp --since 24h
+  kubectl dpm audit --pod 'webapp-*' --since 2025-01-01T00:00:00Z -o json`,
+		Args: cobra.NoArgs,
+
+		RunE: func(c *cobra.Command, _ []string) error {
+			if flagOutput != "text" && flagOutput != "json" {
+				return fmt.Errorf("unknown output format %q (valid formats: text, json)", flagOutput)
+			}
+
+			var err error
+			if filter.Since, err = parseAuditTime(flagSince); err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			if filter.Until, err = parseAuditTime(flagUntil); err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+
+			if err := config.GenerateConfig(); err != nil {
+				return fmt.Errorf("generate config: %w", err)
+			}
+
+			entries, err := audit.Read(auditLogPath(), &filter)
+			if err != nil {
+				return err
+			}
+
+			if flagOutput == "json" {
+				enc := json.NewEncoder(c.OutOrStdout())
+				for i := range entries {
+					if err := enc.Encode(&entries[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			return printAuditEntries(c.OutOrStdout(), entries)
+		},
+	}
+
+	flags := auditCmd.Flags()
+	flags.StringVarP(&filter.Profile, profileFlagName, "p", "", "only sessions of the profile (name or shell pattern)")
+	flags.StringVar(&filter.Pod, "pod", "", "only sessions of the pod (name or shell pattern)")
+	flags.StringVar(&flagSince, "since", "", "only sessions started after the time (RFC3339) or within the duration (e.g. 24h)")
+	flags.StringVar(&flagUntil, "until", "", "only sessions started before the time (RFC3339) or the duration ago")
+	flags.StringVarP(&flagOutput, "output", "o", "text", "output format (text, json)")
+
+	return auditCmd
+}
+
+// parseAuditTime parses a RFC3339 time or a duration before now, an empty string is the zero time
+func parseAuditTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+func printAuditEntries(out io.Writer, entries []audit.Entry) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "TIME\tCONTEXT\tUSER\tPROFILE\tNAMESPACE\tPOD\tEXIT\tDURATION")
+	for _, e := range entries {
+		exit := fmt.Sprint(e.ExitCode)
+		if e.Denied {
+			exit = "denied"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Timestamp.Local().Format(time.DateTime), e.Context, e.User, e.Profile, e.Namespace, e.Pod, exit,
+			(time.Duration(e.Duration * float64(time.Second))).Round(time.Second))
+	}
+
+	return w.Flush()
+}
+
+// auditLogPath returns the configured audit log
+func auditLogPath() string {
+	if profile.Config.Audit.Path != "" {
+		return os.ExpandEnv(profile.Config.Audit.Path)
+	}
+	return audit.DefaultPath()
+}
+
+// newAuditEntry describes the debug session of the debug profile,
+// details which can't be resolved stay empty
+func newAuditEntry(ctx context.Context, namespace, podName string) *audit.Entry {
+	e := &audit.Entry{
+		Timestamp:       time.Now(),
+		Profile:         debugProfile.ProfileName,
+		SourceType:      debugProfile.ProfileSource.Type,
+		Namespace:       namespace,
+		Pod:             strings.TrimPrefix(podName, "pod/"),
+		TargetContainer: debugProfile.TargetContainer,
+		Image:           debugProfile.Image,
+	}
+
+	if debugProfile.GetSource() != nil {
+		e.SourceType = debugProfile.GetSource().Type()
+	}
+
+	if kubeContext, err := currentKubeContext(); err == nil {
+		e.Context = kubeContext.Name
+		e.Cluster = kubeContext.Cluster
+		e.User = kubeContext.User
+	}
+
+	if digest, err := debugProfile.SpecDigest(ctx); err == nil {
+		e.SpecDigest = digest
+	}
+
+	return e
+}
+
+// recordAudit appends the entry to the audit log, a failure only gets reported
+func recordAudit(e *audit.Entry, streams genericiooptions.IOStreams) {
+	if err := audit.Append(auditLogPath(), e); err != nil {
+		fmt.Fprintf(streams.ErrOut, "warning: record debug session in audit log: %v\n", err)
+	}
+}
+
+// exitCode returns the exit code of a finished command, -1 if it couldn't be started
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	// exit status of an attached container
+	var statusErr interface{ ExitStatus() int }
+	if errors.As(err, &statusErr) {
+		return statusErr.ExitStatus()
+	}
+
+	return -1
+}
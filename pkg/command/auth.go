@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func Auth() *cobra.Command {
+	authCmd := &cobra.Command{
+		Use:   "auth",
+		Short: "check the permissions needed for debug sessions",
+	}
+
+	authCmd.AddCommand(authCanI())
+
+	return authCmd
+}
+
+func authCanI() *cobra.Command {
+	return &cobra.Command{
+		Use:   "can-i PROFILE [POD]",
+		Short: "check whether you have all permissions to run a profile",
+		Long: "check with SelfSubjectAccessReviews whether you have all permissions 'kubectl dpm run' needs for the profile, " +
+			"without creating a debug container. The command fails if a permission is missing.",
+		Example: `  kubectl dpm auth can-i webapp
+  kubectl dpm auth can-i netadmin webapp-7d9c6b5f4-x2x9q --namespace default`,
+		Args: cobra.RangeArgs(1, 2),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := config.GenerateConfig(); err != nil {
+				return fmt.Errorf("generate config: %w", err)
+			}
+
+			if err := profile.CompleteProfile(args[0]); err != nil {
+				return fmt.Errorf("complete profile: %w", err)
+			}
+
+			idx, err := profile.GetProfileIdx(args[0])
+			if err != nil {
+				return err
+			}
+			debugProfile = profile.Config.Profiles[idx]
+
+			podName := ""
+			if len(args) == 2 {
+				podName = strings.TrimPrefix(args[1], "pod/")
+			}
+
+			clientset, err := newClientset()
+			if err != nil {
+				return err
+			}
+
+			namespaces, err := targetNamespaces(c.Context(), clientset)
+			if err != nil {
+				return err
+			}
+
+			var checks []profile.AccessCheck
+			for _, namespace := range namespaces {
+				checks = append(checks, profile.RequiredAccess(&debugProfile, namespace, podName)...)
+			}
+
+			results, err := profile.CheckAccess(c.Context(), clientset.AuthorizationV1(), checks)
+			if err != nil {
+				return err
+			}
+
+			if err := printAccessResults(c.OutOrStdout(), results); err != nil {
+				return err
+			}
+
+			c.SilenceUsage = true
+			for _, r := range results {
+				if !r.Allowed {
+					return fmt.Errorf("missing permissions to run profile %q", args[0])
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+func printAccessResults(out io.Writer, results []profile.AccessResult) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ALLOWED\tVERB\tRESOURCE\tNAME\tNAMESPACE\tPURPOSE")
+	for _, r := range results {
+		allowed := "yes"
+		if !r.Allowed {
+			allowed = "no"
+			if r.Reason != "" {
+				allowed += " (" + r.Reason + ")"
+			}
+		}
+
+		resource := r.Resource
+		if r.Subresource != "" {
+			resource += "/" + r.Subresource
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", allowed, r.Verb, resource, r.Name, r.Namespace, r.Purpose)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/collect"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func Collect() *cobra.Command {
+	var (
+		flagPaths     []string
+		flagOutputDir string
+	)
+
+	collectCmd := &cobra.Command{
+		Use:   "collect POD [CONTAINER]",
+		Short: "copy files from a running debug session",
+		Long: "copy the collect paths of the profile (or --path) from a running debug session to a local timestamped directory. " +
+			"Without a container the latest running debug session of the pod is used.",
+		Example: `  kubectl dpm collect webapp-7d9c6b5f4-x2x9q
+  kubectl dpm collect webapp-7d9c6b5f4-x2x9q dpm-netadmin-q8z4m --path /tmp/capture.pcap`,
+		Args: cobra.RangeArgs(1, 2),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := config.GenerateConfig(); err != nil {
+				return fmt.Errorf("generate config: %w", err)
+			}
+
+			session, err := findSession(c.Context(), args, true)
+			if err != nil {
+				return err
+			}
+
+			if !session.Running {
+				return fmt.Errorf("debug session %s of pod %s is not running (%s)", session.Container, session.Pod, session.State)
+			}
+
+			paths := flagPaths
+			if len(paths) == 0 {
+				p, err := profile.SessionProfile(session)
+				if err != nil {
+					return fmt.Errorf("%w - use --path to name the files", err)
+				}
+				if len(p.Collect) == 0 {
+					return fmt.Errorf("profile %q has no collect paths - use --path to name the files", p.ProfileName)
+				}
+				paths = p.Collect
+			}
+
+			outputDir := flagOutputDir
+			if outputDir == "" {
+				outputDir = profile.ArtifactsDir()
+			}
+
+			_, err = collectSession(c.Context(), session.Namespace, session.Pod, session.Container, paths, outputDir, c.ErrOrStderr())
+			return err
+		},
+	}
+
+	collectCmd.Flags().StringArrayVar(&flagPaths, "path", nil, "absolute path to copy instead of the collect paths of the profile, can be repeated")
+	collectCmd.Flags().StringVarP(&flagOutputDir, "output-dir", "o", "", "directory of the timestamped artifact directories (default: artifacts.dir)")
+
+	return collectCmd
+}
+
+// collectSession copies the paths of the container into a new timestamped directory
+// below dir with tar over exec and returns the directory
+func collectSession(ctx context.Context, namespace, podName, container string, paths []string, dir string, errOut io.Writer) (string, error) {
+	podName = strings.TrimPrefix(podName, "pod/")
+	target := filepath.Join(dir, fmt.Sprintf("%s-%s-%s", time.Now().UTC().Format("20060102T150405Z"), podName, container))
+
+	if err := os.MkdirAll(target, 0o700); err != nil {
+		return "", fmt.Errorf("create artifact directory: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	var stderr bytes.Buffer
+
+	execErr := make(chan error, 1)
+	go func() {
+		err := execInContainer(ctx, namespace, podName, container, collect.TarCommand(paths), pw, &stderr)
+		pw.CloseWithError(err)
+		execErr <- err
+	}()
+
+	files, err := collect.Extract(pr, target)
+	// drain the stream so tar can finish after an extraction error
+	_, _ = io.Copy(io.Discard, pr)
+	if tarErr := <-execErr; tarErr != nil {
+		// tar fails if some of the paths don't exist, the other files are still copied
+		fmt.Fprintf(errOut, "warning: tar in debug container %s: %v %s\n", container, tarErr, strings.TrimSpace(stderr.String()))
+	}
+
+	if err != nil {
+		return target, fmt.Errorf("collect files from debug container %s: %w", container, err)
+	}
+
+	if len(files) == 0 {
+		_ = os.Remove(target)
+		return "", fmt.Errorf("no files collected from debug container %s", container)
+	}
+
+	fmt.Fprintf(errOut, "collected %d file(s) from %s to %s\n", len(files), container, target)
+
+	return target, nil
+}
+
+// collectAfterRun copies the collect paths of the debug profile after the session ended,
+// which is only possible as long as the debug container is still running
+func collectAfterRun(ctx context.Context, namespace, podName, container string, streams genericiooptions.IOStreams) string {
+	clientset, err := newClientset()
+	if err != nil {
+		fmt.Fprintf(streams.ErrOut, "warning: collect files: %v\n", err)
+		return ""
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, strings.TrimPrefix(podName, "pod/"), metav1.GetOptions{})
+	if err != nil {
+		fmt.Fprintf(streams.ErrOut, "warning: collect files: get pod: %v\n", err)
+		return ""
+	}
+
+	session, err := selectSession(pod, container, false)
+	if err != nil {
+		fmt.Fprintf(streams.ErrOut, "warning: collect files: %v\n", err)
+		return ""
+	}
+
+	if !session.Running {
+		fmt.Fprintf(streams.ErrOut, "warning: files of debug container %s can't be collected, it is not running anymore (%s) - "+
+			"use 'kubectl dpm collect %s %s' from another terminal before leaving the shell\n",
+			container, session.State, session.Pod, container)
+		return ""
+	}
+
+	dir, err := collectSession(ctx, namespace, podName, container, debugProfile.Collect, profile.ArtifactsDir(), streams.ErrOut)
+	if err != nil {
+		fmt.Fprintf(streams.ErrOut, "warning: %v\n", err)
+	}
+
+	return dir
+}
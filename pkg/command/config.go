@@ -3,12 +3,30 @@
 package command
 
 var (
-	flagProfileName string
-	flagImage       string
-	flagDebug       bool
-	flagVerboseList bool
+	flagProfileName  string
+	flagImage        string
+	flagDebug        bool
+	flagVerboseList  bool
+	flagAllContexts  bool
+	flagForce        bool
+	flagAllowRisky   bool
+	flagRecord       bool
+	flagAll          bool
+	flagParallel     int
+	flagAllOutputDir string
+	flagSelector     string
+	flagTarget       string
+	flagPullPolicy   string
+	flagEnv          []string
+	flagSet          []string
+	flagDryRun       bool
+	flagAdHocSpec    string
+	flagAdHocSource  string
 )
 
 const (
-	profileFlagName = "profile"
+	profileFlagName     = "profile"
+	allContextsFlagName = "all-contexts"
+	specFlagName        = "spec"
+	sourceFlagName      = "source"
 )
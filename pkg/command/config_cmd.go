@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+)
+
+func Config() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "inspect and maintain the configuration file",
+	}
+
+	configCmd.AddCommand(configSchema())
+	configCmd.AddCommand(configMigrate())
+
+	return configCmd
+}
+
+func configSchema() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "print the JSON Schema of the configuration file",
+		Args:  cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := cmd.OutOrStdout().Write(config.Schema()); err != nil {
+				return fmt.Errorf("print schema: %w", err)
+			}
+
+			return nil
+		},
+	}
+}
+
+func configMigrate() *cobra.Command {
+	var (
+		flagWrite  bool
+		flagDiff   bool
+		flagBackup bool
+	)
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "convert legacy profile entries into profileSource entries",
+		Long: "convert legacy profile entries into profileSource entries. Built-in kubectl profile names become " +
+			"a builtin source, paths become a file source. The migrated config is printed to stdout unless --write is set.",
+		Args: cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			original, err := os.ReadFile(config.ConfigurationFile)
+			if err != nil {
+				return fmt.Errorf("read config file: %w", err)
+			}
+
+			migrated, migrations, err := config.MigrateLegacyProfiles(original)
+			if err != nil {
+				return fmt.Errorf("migrate config file %q: %w", config.ConfigurationFile, err)
+			}
+
+			for _, m := range migrations {
+				if m.Dropped {
+					fmt.Fprintf(cmd.ErrOrStderr(), "profile %q: %q dropped, profileSource already set\n", m.ProfileName, m.Value)
+					continue
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "profile %q: %q -> %s\n", m.ProfileName, m.Value, m.SourceType)
+			}
+
+			switch {
+			case flagDiff:
+				diff, err := config.Diff(config.ConfigurationFile, original, migrated)
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(cmd.OutOrStdout(), diff)
+			case flagWrite:
+				if len(migrations) == 0 {
+					fmt.Fprintln(cmd.ErrOrStderr(), "nothing to migrate")
+					return nil
+				}
+				if err := writeConfigFile(cmd, original, migrated, flagBackup); err != nil {
+					return err
+				}
+			default:
+				if _, err := cmd.OutOrStdout().Write(migrated); err != nil {
+					return fmt.Errorf("print migrated config: %w", err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	migrateCmd.Flags().BoolVar(&flagWrite, "write", false, "rewrite the config file in place")
+	migrateCmd.Flags().BoolVar(&flagDiff, "diff", false, "only print a diff of the changes")
+	migrateCmd.Flags().BoolVar(&flagBackup, "backup", true, "keep a copy of the original config file as <config>.bak when using --write")
+
+	migrateCmd.MarkFlagsMutuallyExclusive("write", "diff")
+
+	return migrateCmd
+}
+
+// writeConfigFile replaces the config file with data and optionally keeps a backup of the original
+func writeConfigFile(cmd *cobra.Command, original, data []byte, backup bool) error {
+	info, err := os.Stat(config.ConfigurationFile)
+	if err != nil {
+		return fmt.Errorf("stat config file: %w", err)
+	}
+
+	if backup {
+		backupFile := config.ConfigurationFile + ".bak"
+		if err := os.WriteFile(backupFile, original, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("write backup %q: %w", backupFile, err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "backup written to %s\n", backupFile)
+	}
+
+	if err := os.WriteFile(config.ConfigurationFile, data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("write config file: %w", err)
+	}
+
+	return nil
+}
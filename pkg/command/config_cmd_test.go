@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+)
+
+func TestConfigMigrate(t *testing.T) {
+	const legacy = "profiles:\n  - name: netadmin\n    profile: netadmin\n"
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantErr   bool
+		wantWrite bool
+	}{
+		{name: "print", args: nil},
+		{name: "diff", args: []string{"--diff"}},
+		{name: "write", args: []string{"--write", "--backup=false"}, wantWrite: true},
+		{name: "diff and write", args: []string{"--diff", "--write"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "debug-profiles.yaml")
+			if err := os.WriteFile(file, []byte(legacy), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			previous := config.ConfigurationFile
+			config.ConfigurationFile = file
+			t.Cleanup(func() { config.ConfigurationFile = previous })
+
+			root := Root()
+			root.AddCommand(Config())
+			root.SetArgs(append([]string{"config", "migrate"}, tt.args...))
+			root.SetOut(&strings.Builder{})
+			root.SetErr(&strings.Builder{})
+
+			err := root.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("config migrate %v error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if written := string(data) != legacy; written != tt.wantWrite {
+				t.Errorf("config migrate %v wrote the config file = %v, want %v:\n%s", tt.args, written, tt.wantWrite, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+// targetPod is a target pod of run --all
+type targetPod struct {
+	Namespace string
+	Name      string
+	// ID identifies the pod in the output, it contains the namespace if several namespaces are searched
+	ID string
+}
+
+// podResult is the result of the debug profile in a single pod of run --all
+type podResult struct {
+	Pod      string
+	ExitCode int
+	Err      error
+}
+
+// splitCommand splits the arguments into the target and the command after --
+func splitCommand(c *cobra.Command, args []string) ([]string, []string) {
+	dash := c.ArgsLenAtDash()
+	if dash < 0 {
+		return args, nil
+	}
+	return args[:dash], args[dash:]
+}
+
+// runAll runs the debug profile non-interactively in all pods of the target workload
+// or, without target, in all pods selected by the workload and the selectors of the profile
+func runAll(ctx context.Context, args, command []string, streams genericiooptions.IOStreams) error {
+	podName, target, err := parseTarget(args)
+	if err != nil {
+		return err
+	}
+	if podName != "" {
+		return fmt.Errorf("--all runs the profile in all pods of a workload and accepts no pod")
+	}
+
+	if err := prepareRun(ctx); err != nil {
+		return err
+	}
+
+	record, err := debugProfile.ShouldRecord(flagRecord)
+	if err != nil {
+		return err
+	}
+	if record {
+		return fmt.Errorf("recording isn't supported with --all, use --output-dir to keep the output")
+	}
+
+	pods, err := allTargetPods(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	// all pods share the profile, so the environment is inherited from the first pod and it's linted once
+	if debugProfile.InheritEnvFrom != "" && slices.ContainsFunc(pods, func(p targetPod) bool { return p.Namespace != pods[0].Namespace }) {
+		return fmt.Errorf("inheritEnvFrom of profile %q isn't supported with --all in several namespaces", debugProfile.ProfileName)
+	}
+
+	firstPod := fetchPod(ctx, pods[0].Namespace, pods[0].Name)
+	if err := debugProfile.InheritEnv(firstPod); err != nil {
+		return err
+	}
+
+	if err := lintBeforeRun(ctx, firstPod, streams); err != nil {
+		return err
+	}
+
+	if flagDryRun {
+		return printDryRun(ctx, streams.Out, pods)
+	}
+
+	if flagAllOutputDir != "" {
+		if err := os.MkdirAll(flagAllOutputDir, 0o700); err != nil {
+			return fmt.Errorf("create output directory: %w", err)
+		}
+	}
+
+	profileArgs, cleanup, err := kubectlProfileArgs(ctx, streams)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var (
+		outMu   sync.Mutex
+		wg      sync.WaitGroup
+		results = make([]podResult, len(pods))
+		workers = make(chan struct{}, max(flagParallel, 1))
+	)
+
+	for i, pod := range pods {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case workers <- struct{}{}:
+				defer func() { <-workers }()
+			case <-ctx.Done():
+				results[i] = podResult{Pod: pod.ID, ExitCode: -1, Err: ctx.Err()}
+				return
+			}
+
+			out := newPrefixWriter(streams.Out, &outMu, "["+pod.ID+"] ")
+			errOut := newPrefixWriter(streams.ErrOut, &outMu, "["+pod.ID+"] ")
+			defer out.Flush()
+			defer errOut.Flush()
+
+			results[i] = runInPod(ctx, pod, profileArgs, command, out, errOut)
+			if results[i].Err != nil {
+				fmt.Fprintf(errOut, "error: %v\n", results[i].Err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return summarizeResults(streams.Out, results)
+}
+
+// allTargetPods returns the target pods of run --all in all namespaces of the debug profile
+// and checks the permissions in every namespace with target pods
+func allTargetPods(ctx context.Context, target *profile.WorkloadRef) ([]targetPod, error) {
+	clientset, err := newClientset()
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := targetNamespaces(ctx, clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []namespacePods
+	if len(namespaces) == 1 {
+		// check the permissions before kubectl fails in the middle of the session
+		if err := preflight(ctx, namespaces[0], ""); err != nil {
+			return nil, err
+		}
+
+		pods, err := getTargetPods(ctx, clientset, namespaces[0], target)
+		if err != nil {
+			return nil, fmt.Errorf("get target pods in namespace %q: %w", namespaces[0], err)
+		}
+		found = []namespacePods{{Namespace: namespaces[0], Pods: pods}}
+	} else {
+		if found, err = searchNamespaces(ctx, clientset, namespaces, "", target); err != nil {
+			return nil, err
+		}
+		for _, ns := range found {
+			if err := preflight(ctx, ns.Namespace, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var pods []targetPod
+	for _, ns := range found {
+		for _, pod := range ns.Pods {
+			id := pod
+			if len(namespaces) > 1 {
+				id = ns.Namespace + "/" + pod
+			}
+			pods = append(pods, targetPod{Namespace: ns.Namespace, Name: pod, ID: id})
+		}
+	}
+
+	return pods, nil
+}
+
+// runInPod runs the debug profile in the pod without attaching and streams the logs of the debug container
+func runInPod(ctx context.Context, target targetPod, profileArgs, command []string, out, errOut io.Writer) podResult {
+	result := podResult{Pod: target.ID, ExitCode: -1}
+	namespace, pod := target.Namespace, target.Name
+
+	podStreams := genericiooptions.IOStreams{Out: out, ErrOut: errOut}
+
+	auditEntry := newAuditEntry(ctx, namespace, pod)
+	if err := checkPolicy(ctx, namespace, fetchPod(ctx, namespace, pod), auditEntry, podStreams); err != nil {
+		result.Err = err
+		return result
+	}
+
+	sessionContainer := profile.SessionContainerName(debugProfile.ProfileName)
+	auditEntry.Container = sessionContainer
+
+	if profile.Config.Sessions.Annotate {
+		annotateSession(ctx, namespace, pod, sessionContainer, auditEntry, podStreams)
+	}
+
+	debugArgs := append([]string{"debug", "--namespace", namespace}, profileArgs...)
+	debugArgs = append(debugArgs, containerArgs(sessionContainer)...)
+	debugArgs = append(debugArgs, pod, "--attach=false", "--quiet")
+	if len(command) > 0 {
+		debugArgs = append(append(debugArgs, "--"), command...)
+	}
+
+	var kubectlOut bytes.Buffer
+	debugCommand := newDebugCommand(debugArgs)
+	debugCommand.Stdout = &kubectlOut
+	debugCommand.Stderr = &kubectlOut
+
+	auditEntry.Timestamp = time.Now()
+	err := debugCommand.Run()
+	if err != nil {
+		err = fmt.Errorf("kubectl debug: %w: %s", err, bytes.TrimSpace(kubectlOut.Bytes()))
+	} else {
+		result.ExitCode, err = streamToOutputDir(ctx, target, sessionContainer, out, errOut)
+	}
+	result.Err = err
+
+	auditEntry.Duration = time.Since(auditEntry.Timestamp).Seconds()
+	auditEntry.ExitCode = result.ExitCode
+	if err != nil {
+		auditEntry.Error = err.Error()
+	}
+	recordAudit(auditEntry, podStreams)
+
+	return result
+}
+
+// streamToOutputDir streams the debug container and also writes its output to
+// <dir>/<id>.log if an output directory is set
+func streamToOutputDir(ctx context.Context, target targetPod, container string, out, errOut io.Writer) (int, error) {
+	if flagAllOutputDir != "" {
+		file := filepath.Join(flagAllOutputDir, target.ID+".log")
+		if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+			return -1, fmt.Errorf("create output directory: %w", err)
+		}
+
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			return -1, fmt.Errorf("create output file: %w", err)
+		}
+		defer f.Close()
+
+		out = io.MultiWriter(out, f)
+	}
+
+	return streamLimitedSession(ctx, target.Namespace, target.Name, container, out, errOut)
+}
+
+// streamLimitedSession streams the debug container for at most the max duration of the profile
+func streamLimitedSession(ctx context.Context, namespace, pod, container string, out, errOut io.Writer) (int, error) {
+	limits := debugProfile.SessionLimits()
+	if limits.MaxDuration <= 0 {
+		return streamSession(ctx, namespace, pod, container, out)
+	}
+
+	streamCtx, cancel := context.WithTimeout(ctx, limits.MaxDuration)
+	defer cancel()
+
+	exitCode, err := streamSession(streamCtx, namespace, pod, container, out)
+	if err != nil && ctx.Err() == nil && errors.Is(streamCtx.Err(), context.DeadlineExceeded) {
+		err = &sessionLimitError{limit: "max duration", value: limits.MaxDuration}
+		if limits.Kill {
+			killSession(ctx, namespace, pod, container, genericiooptions.IOStreams{ErrOut: errOut})
+		}
+	}
+
+	return exitCode, err
+}
+
+// streamSession copies the logs of the debug container to out and returns its exit code
+func streamSession(ctx context.Context, namespace, pod, container string, out io.Writer) (int, error) {
+	clientset, err := newClientset()
+	if err != nil {
+		return -1, err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, sessionStartTimeout)
+	defer cancel()
+
+	if _, err := waitForContainerState(waitCtx, clientset, namespace, pod, container, func(state *corev1.ContainerState) (bool, error) {
+		return state.Running != nil || state.Terminated != nil, nil
+	}); err != nil {
+		return -1, err
+	}
+
+	logs, err := clientset.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: container, Follow: true}).Stream(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("get logs of debug container %s: %w", container, err)
+	}
+	defer logs.Close()
+
+	if _, err := io.Copy(out, logs); err != nil {
+		return -1, fmt.Errorf("stream logs of debug container %s: %w", container, err)
+	}
+
+	state, err := waitForContainerState(ctx, clientset, namespace, pod, container, func(state *corev1.ContainerState) (bool, error) {
+		return state.Terminated != nil, nil
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return int(state.Terminated.ExitCode), nil
+}
+
+// summarizeResults prints the exit code of every pod and fails if the profile failed in any pod
+func summarizeResults(out io.Writer, results []podResult) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "POD\tEXIT CODE\tERROR")
+
+	failed := 0
+	for _, r := range results {
+		exitCode, errMsg := fmt.Sprint(r.ExitCode), "-"
+		if r.Err != nil {
+			exitCode, errMsg = "-", r.Err.Error()
+		}
+		if r.Err != nil || r.ExitCode != 0 {
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Pod, exitCode, errMsg)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("profile %q failed in %d of %d pods", debugProfile.ProfileName, failed, len(results))
+	}
+
+	return nil
+}
+
+// prefixWriter prefixes every line, complete lines of all writers sharing the mutex don't interleave
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+func newPrefixWriter(w io.Writer, mu *sync.Mutex, prefix string) *prefixWriter {
+	return &prefixWriter{w: w, mu: mu, prefix: prefix}
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+
+	i := bytes.LastIndexByte(p.buf, '\n')
+	if i < 0 {
+		return len(data), nil
+	}
+
+	if err := p.writeLines(p.buf[:i+1]); err != nil {
+		return 0, err
+	}
+	p.buf = append(p.buf[:0], p.buf[i+1:]...)
+
+	return len(data), nil
+}
+
+// Flush writes an incomplete last line
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	err := p.writeLines(append(p.buf, '\n'))
+	p.buf = p.buf[:0]
+	return err
+}
+
+func (p *prefixWriter) writeLines(lines []byte) error {
+	var b bytes.Buffer
+	for line := range bytes.Lines(lines) {
+		b.WriteString(p.prefix)
+		b.Write(line)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := p.w.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var (
+		out bytes.Buffer
+		mu  sync.Mutex
+	)
+
+	a := newPrefixWriter(&out, &mu, "[webapp-0] ")
+	b := newPrefixWriter(&out, &mu, "[webapp-1] ")
+
+	for _, w := range []struct {
+		w    *prefixWriter
+		data string
+	}{
+		{a, "LISTEN 0 4096 "},
+		{b, "LISTEN 0 128 *:9090\n"},
+		{a, "*:8080\nLISTEN"},
+		{b, "done\n"},
+	} {
+		if _, err := w.w.Write([]byte(w.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := a.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[webapp-1] LISTEN 0 128 *:9090\n" +
+		"[webapp-0] LISTEN 0 4096 *:8080\n" +
+		"[webapp-1] done\n" +
+		"[webapp-0] LISTEN\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestSummarizeResults(t *testing.T) {
+	var out bytes.Buffer
+
+	err := summarizeResults(&out, []podResult{
+		{Pod: "webapp-0", ExitCode: 0},
+		{Pod: "webapp-1", ExitCode: 2},
+		{Pod: "webapp-2", ExitCode: -1, Err: errors.New("profile \"netadmin\" denied by policy")},
+	})
+
+	want := `POD       EXIT CODE  ERROR
+webapp-0  0          -
+webapp-1  2          -
+webapp-2  -          profile "netadmin" denied by policy
+`
+	if out.String() != want {
+		t.Errorf("summarizeResults() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	if err == nil || err.Error() != `profile "" failed in 2 of 3 pods` {
+		t.Errorf("summarizeResults() error = %v", err)
+	}
+
+	if err := summarizeResults(&bytes.Buffer{}, []podResult{{Pod: "webapp-0"}}); err != nil {
+		t.Errorf("summarizeResults() error = %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+const defaultDebugImage = "nicolaka/netshoot:v0.13"
+
+func Init() *cobra.Command {
+	var (
+		flagScan      bool
+		flagYes       bool
+		flagOverwrite bool
+		flagImg       string
+	)
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "create a new configuration file",
+		Long: "create a new configuration file. kubectl is detected on PATH and with --scan the Deployments and " +
+			"StatefulSets of the current namespace are offered as profile skeletons.",
+		Args: cobra.NoArgs,
+
+		RunE: func(c *cobra.Command, _ []string) error {
+			if _, err := os.Stat(config.ConfigurationFile); err == nil && !flagOverwrite {
+				return fmt.Errorf("config file %q already exists - use --force to overwrite it", config.ConfigurationFile)
+			}
+
+			kubectlPath, err := exec.LookPath("kubectl")
+			if err != nil {
+				fmt.Fprintln(c.ErrOrStderr(), "warning: kubectl not found on PATH")
+			}
+
+			specDir := filepath.Join(filepath.Dir(config.ConfigurationFile), "profiles")
+
+			var skeletons []profile.Skeleton
+			if flagScan {
+				skeletons, err = scanWorkloads(c.Context(), specDir, flagImg)
+				if err != nil {
+					return fmt.Errorf("scan workloads: %w", err)
+				}
+			}
+
+			// let the user adjust the kubectl path and pick the profiles to create
+			if !flagYes {
+				wizard := newInitModel(kubectlPath, skeletons)
+				result, err := tea.NewProgram(wizard).Run()
+				if err != nil {
+					return fmt.Errorf("error running program: %w", err)
+				}
+
+				wizard, ok := result.(initModel)
+				if !ok || wizard.aborted {
+					return errors.New("init aborted - no config file written")
+				}
+
+				kubectlPath = wizard.kubectlPath()
+				skeletons = wizard.selectedSkeletons()
+			}
+
+			if err := writeInitConfig(kubectlPath, skeletons); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(c.ErrOrStderr(), "config file written to %s\n", config.ConfigurationFile)
+
+			return nil
+		},
+	}
+
+	initCmd.Flags().BoolVar(&flagScan, "scan", false, "offer profiles for the Deployments and StatefulSets of the current namespace")
+	initCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "don't ask, use the detected kubectl and all scanned workloads")
+	initCmd.Flags().BoolVar(&flagOverwrite, "force", false, "overwrite an existing config file")
+	initCmd.Flags().StringVar(&flagImg, "image", defaultDebugImage, "debug container image of the generated profiles")
+
+	return initCmd
+}
+
+// scanWorkloads discovers the profile skeletons of the current namespace
+func scanWorkloads(ctx context.Context, specDir, image string) ([]profile.Skeleton, error) {
+	namespace, _, err := MatchVersionKubeConfigFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("get namespace: %w", err)
+	}
+
+	restConfig, err := MatchVersionKubeConfigFlags.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("get REST config: %w", err)
+	}
+
+	appsClient, err := appsv1client.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create apps client: %w", err)
+	}
+
+	return discoverSkeletons(ctx, appsClient, namespace, specDir, image)
+}
+
+// discoverSkeletons generates a profile skeleton for the first container of every
+// Deployment and StatefulSet in the namespace. Profiles of workloads sharing a name
+// get the short name of their kind as suffix, e.g. webapp-sts.
+func discoverSkeletons(ctx context.Context, appsClient appsv1client.AppsV1Interface, namespace, specDir, image string) ([]profile.Skeleton, error) {
+	var skeletons []profile.Skeleton
+
+	taken := func(name string) bool {
+		return slices.ContainsFunc(skeletons, func(s profile.Skeleton) bool { return s.Profile.ProfileName == name })
+	}
+
+	add := func(kind, shortKind, name string, selector *metav1.LabelSelector, podSpec *corev1.PodSpec) error {
+		if len(podSpec.Containers) == 0 {
+			return nil
+		}
+
+		profileName := name
+		if taken(profileName) {
+			profileName = name + "-" + shortKind
+		}
+		for i := 2; taken(profileName); i++ {
+			profileName = fmt.Sprintf("%s-%s-%d", name, shortKind, i)
+		}
+
+		skeleton, err := profile.NewSkeleton(kind+"/"+name, profileName, namespace, selector, &podSpec.Containers[0], specDir, profile.SkeletonOptions{})
+		if err != nil {
+			return err
+		}
+		skeleton.Profile.Image = image
+
+		skeletons = append(skeletons, skeleton)
+
+		return nil
+	}
+
+	deployments, err := appsClient.Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list deployments in namespace %q: %w", namespace, err)
+	}
+
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if err := add("deployment", "deploy", d.Name, d.Spec.Selector, &d.Spec.Template.Spec); err != nil {
+			return nil, err
+		}
+	}
+
+	statefulSets, err := appsClient.StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list statefulsets in namespace %q: %w", namespace, err)
+	}
+
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		if err := add("statefulset", "sts", s.Name, s.Spec.Selector, &s.Spec.Template.Spec); err != nil {
+			return nil, err
+		}
+	}
+
+	return skeletons, nil
+}
+
+// writeInitConfig writes the config file and the custom profile specs of all skeletons
+func writeInitConfig(kubectlPath string, skeletons []profile.Skeleton) error {
+	data, err := config.RenderConfig(kubectlPath, skeletons)
+	if err != nil {
+		return err
+	}
+
+	// never write a config file which can't be loaded afterwards
+	if err := config.ValidateSchema(config.ConfigurationFile, data); err != nil {
+		return fmt.Errorf("generated config is invalid: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.ConfigurationFile), 0o700); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+
+	for _, s := range skeletons {
+		specPath := s.Profile.ProfileSource.Path
+		if err := os.MkdirAll(filepath.Dir(specPath), 0o700); err != nil {
+			return fmt.Errorf("create profile directory: %w", err)
+		}
+		if err := os.WriteFile(specPath, s.Spec, 0o600); err != nil {
+			return fmt.Errorf("write profile spec %q: %w", specPath, err)
+		}
+	}
+
+	if err := os.WriteFile(config.ConfigurationFile, data, 0o600); err != nil {
+		return fmt.Errorf("write config file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiscoverSkeletons(t *testing.T) {
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}}
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "webapp"}}
+
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Selector: selector, Template: template},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Selector: selector, Template: template},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "webapp", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Selector: selector, Template: template},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "webapp-sts", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Selector: selector, Template: template},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "webapp", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Selector: selector, Template: template},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Selector: selector, Template: template},
+		},
+	)
+
+	skeletons, err := discoverSkeletons(context.Background(), client.AppsV1(), "default", "/profiles", "busybox")
+	if err != nil {
+		t.Fatalf("discoverSkeletons() error = %v", err)
+	}
+
+	var names, paths []string
+	for _, s := range skeletons {
+		names = append(names, s.Origin+"="+s.Profile.ProfileName)
+		paths = append(paths, s.Profile.ProfileSource.Path)
+	}
+
+	want := []string{
+		"deployment/api=api",
+		"deployment/webapp=webapp",
+		"deployment/webapp-sts=webapp-sts",
+		"statefulset/api=api-sts",
+		"statefulset/postgres=postgres",
+		"statefulset/webapp=webapp-sts-2",
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("discoverSkeletons() = %v, want %v", names, want)
+	}
+
+	slices.Sort(paths)
+	if len(slices.Compact(paths)) != len(skeletons) {
+		t.Errorf("discoverSkeletons() spec paths aren't unique: %v", paths)
+	}
+}
@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"strings"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/table"
+)
+
+// initStep is the current step of the init wizard
+type initStep int
+
+const (
+	stepKubectlPath initStep = iota
+	stepProfiles
+)
+
+type initModel struct {
+	step      initStep
+	input     []rune
+	table     bubbletable.Model
+	skeletons []profile.Skeleton
+	selected  map[int]bool
+	aborted   bool
+}
+
+// newInitModel initializes the model of the init wizard
+func newInitModel(kubectlPath string, skeletons []profile.Skeleton) initModel {
+	// all skeletons are selected by default
+	selected := map[int]bool{}
+	for i := range skeletons {
+		selected[i] = true
+	}
+
+	m := initModel{
+		step:      stepKubectlPath,
+		input:     []rune(kubectlPath),
+		skeletons: skeletons,
+		selected:  selected,
+	}
+	m.table = m.generateTable()
+
+	return m
+}
+
+func (m initModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m initModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyCtrlD, tea.KeyEsc:
+			m.aborted = true
+			return m, tea.Quit
+		case tea.KeyEnter:
+			if m.step == stepKubectlPath && len(m.skeletons) > 0 {
+				m.step = stepProfiles
+				return m, nil
+			}
+			return m, tea.Quit
+		case tea.KeySpace:
+			if m.step == stepProfiles {
+				cursor := m.table.Cursor()
+				m.selected[cursor] = !m.selected[cursor]
+				m.table.SetRows(m.generateTable().Rows())
+				return m, nil
+			}
+		}
+	}
+
+	if m.step == stepKubectlPath {
+		m.input = editInput(m.input, msg)
+		return m, nil
+	}
+
+	m.table, cmd = m.table.Update(msg)
+
+	return m, cmd
+}
+
+func (m initModel) View() string {
+	if m.step == stepKubectlPath {
+		return "\n  kubectl path: " + string(m.input) + "█\n\n  enter: confirm • esc: abort\n"
+	}
+
+	return m.table.View() + "\n  space: toggle profile • enter: write config • esc: abort\n"
+}
+
+// kubectlPath returns the confirmed kubectl path
+func (m initModel) kubectlPath() string {
+	return strings.TrimSpace(string(m.input))
+}
+
+// editInput applies basic line editing key presses to the input
+func editInput(input []rune, msg tea.Msg) []rune {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return input
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		return append(input, keyMsg.Runes...)
+	case tea.KeyBackspace:
+		if len(input) > 0 {
+			return input[:len(input)-1]
+		}
+	case tea.KeyCtrlU:
+		return nil
+	}
+
+	return input
+}
+
+// selectedSkeletons returns all skeletons selected by the user
+func (m initModel) selectedSkeletons() []profile.Skeleton {
+	var skeletons []profile.Skeleton
+
+	for i, s := range m.skeletons {
+		if m.selected[i] {
+			skeletons = append(skeletons, s)
+		}
+	}
+
+	return skeletons
+}
+
+// generateTable renders the skeletons with their selection state
+func (m initModel) generateTable() bubbletable.Model {
+	rows := make([]bubbletable.Row, 0, len(m.skeletons))
+	widths := []int{3, len("Name"), len("Workload"), len("MatchLabels")}
+
+	for i, s := range m.skeletons {
+		mark := "[ ]"
+		if m.selected[i] {
+			mark = "[x]"
+		}
+
+		labels := metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: s.Profile.MatchLabels})
+		row := bubbletable.Row{mark, s.Profile.ProfileName, s.Origin, labels}
+
+		for j, cell := range row {
+			widths[j] = max(widths[j], len(cell))
+		}
+
+		rows = append(rows, row)
+	}
+
+	t := bubbletable.New(
+		bubbletable.WithColumns([]bubbletable.Column{
+			{Title: "", Width: widths[0]},
+			{Title: "Name", Width: widths[1]},
+			{Title: "Workload", Width: widths[2]},
+			{Title: "MatchLabels", Width: widths[3]},
+		}),
+		bubbletable.WithRows(rows),
+		bubbletable.WithFocused(true),
+	)
+	table.ConfigureInteractive(&t)
+
+	return t
+}
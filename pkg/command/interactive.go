@@ -4,9 +4,14 @@ package command
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 
 	bubbletable "github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/kubectl/pkg/util/term"
 
 	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
 	"github.com/bavarianbidi/kubectl-dpm/pkg/table"
@@ -24,7 +29,13 @@ func initTeaModel(ctx context.Context) (model, error) {
 		return model{}, err
 	}
 
-	// generate the table with image, namespace and matchLabels columns
+	// hide profiles which are scoped to other kubeconfig contexts
+	interactiveProfiles, err = scopedProfiles(interactiveProfiles)
+	if err != nil {
+		return model{}, err
+	}
+
+	// generate the table with image, namespace and selector columns
 	t := table.GenerateTable(interactiveProfiles, true)
 	table.ConfigureInteractive(&t)
 
@@ -58,3 +69,80 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m model) View() string {
 	return m.table.View() + "\n  " + m.table.HelpView() + "\n"
 }
+
+// namespaceModel lets the user pick the namespace of the target pod
+type namespaceModel struct {
+	table    bubbletable.Model
+	selected int
+}
+
+func newNamespaceModel(found []namespacePods) namespaceModel {
+	rows := make([]bubbletable.Row, 0, len(found))
+	longestNamespace := len("Namespace")
+	for _, ns := range found {
+		longestNamespace = max(longestNamespace, len(ns.Namespace))
+		rows = append(rows, bubbletable.Row{ns.Namespace, strconv.Itoa(len(ns.Pods))})
+	}
+
+	t := bubbletable.New(
+		bubbletable.WithColumns([]bubbletable.Column{
+			{Title: "Namespace", Width: longestNamespace},
+			{Title: "Pods", Width: len("Pods")},
+		}),
+		bubbletable.WithRows(rows),
+		bubbletable.WithHeight(min(len(rows)+1, 15)),
+	)
+	table.ConfigureInteractive(&t)
+
+	return namespaceModel{table: t, selected: -1}
+}
+
+func (m namespaceModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m namespaceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyCtrlD:
+			return m, tea.Quit
+		case tea.KeyEnter:
+			m.selected = m.table.Cursor()
+			return m, tea.Quit
+		}
+	}
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+func (m namespaceModel) View() string {
+	return "  target pods found in several namespaces, pick one:\n" + m.table.View() + "\n  " + m.table.HelpView() + "\n"
+}
+
+// pickNamespace lets the user pick one of the namespaces with target pods, it fails
+// if the input isn't a terminal
+func pickNamespace(found []namespacePods, streams genericiooptions.IOStreams) (namespacePods, error) {
+	names := make([]string, 0, len(found))
+	for _, ns := range found {
+		names = append(names, ns.Namespace)
+	}
+
+	if !(&term.TTY{In: streams.In}).IsTerminalIn() {
+		return namespacePods{}, fmt.Errorf("target pods of profile %q found in namespaces %s, run in a terminal to pick one",
+			debugProfile.ProfileName, strings.Join(names, ", "))
+	}
+
+	result, err := tea.NewProgram(newNamespaceModel(found), tea.WithInput(streams.In), tea.WithOutput(streams.Out)).Run()
+	if err != nil {
+		return namespacePods{}, fmt.Errorf("error running program: %w", err)
+	}
+
+	selected := result.(namespaceModel).selected
+	if selected < 0 {
+		return namespacePods{}, fmt.Errorf("no namespace selected - exiting")
+	}
+
+	return found[selected], nil
+}
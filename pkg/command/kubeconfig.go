@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+// currentKubeContext returns the active kubeconfig context, honoring the
+// --context and --cluster flags
+func currentKubeContext() (profile.KubeContext, error) {
+	rawConfig, err := MatchVersionKubeConfigFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return profile.KubeContext{}, fmt.Errorf("load kubeconfig: %w", err)
+	}
+
+	kubeContext := profile.KubeContext{Name: rawConfig.CurrentContext}
+
+	if kubeConfigFlags != nil && kubeConfigFlags.Context != nil && *kubeConfigFlags.Context != "" {
+		kubeContext.Name = *kubeConfigFlags.Context
+	}
+
+	if c, ok := rawConfig.Contexts[kubeContext.Name]; ok {
+		kubeContext.Cluster = c.Cluster
+		kubeContext.User = c.AuthInfo
+	}
+
+	if kubeConfigFlags != nil && kubeConfigFlags.ClusterName != nil && *kubeConfigFlags.ClusterName != "" {
+		kubeContext.Cluster = *kubeConfigFlags.ClusterName
+	}
+
+	if kubeConfigFlags != nil && kubeConfigFlags.AuthInfoName != nil && *kubeConfigFlags.AuthInfoName != "" {
+		kubeContext.User = *kubeConfigFlags.AuthInfoName
+	}
+
+	return kubeContext, nil
+}
+
+// scopedProfiles returns the profiles usable in the active kubeconfig context,
+// or all profiles if --all-contexts is set
+func scopedProfiles(profiles []profile.Profile) ([]profile.Profile, error) {
+	if flagAllContexts {
+		return profiles, nil
+	}
+
+	kubeContext, err := currentKubeContext()
+	if err != nil {
+		return nil, err
+	}
+
+	return profile.ProfilesForContext(profiles, kubeContext), nil
+}
+
+// newClientset creates a Kubernetes client for the active kubeconfig context
+func newClientset() (kubernetes.Interface, error) {
+	restConfig, err := MatchVersionKubeConfigFlags.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("get REST config: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create k8s clientset: %w", err)
+	}
+
+	return clientset, nil
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+// killTimeout is the time killing the processes of the debug container may take
+const killTimeout = 30 * time.Second
+
+// killScript kills all processes of the debug container except itself. The processes
+// of the debug container share its mount namespace, the processes of a target
+// container with a shared process namespace don't.
+const killScript = `self=$$; mnt=$(readlink /proc/self/ns/mnt)
+for d in /proc/[0-9]*; do
+  p=${d#/proc/}
+  [ "$p" = "$self" ] && continue
+  [ "$(readlink "$d/ns/mnt" 2>/dev/null)" = "$mnt" ] && kill -KILL "$p" 2>/dev/null
+done
+true`
+
+// sessionLimitError is the reason a debug session got ended
+type sessionLimitError struct {
+	limit string
+	value time.Duration
+}
+
+func (e *sessionLimitError) Error() string {
+	return fmt.Sprintf("%s of %s reached", e.limit, e.value)
+}
+
+// sessionWatchdog enforces the limits of an attached debug session
+type sessionWatchdog struct {
+	limits profile.SessionLimits
+	now    func() time.Time
+	start  time.Time
+
+	mu             sync.Mutex
+	lastInput      time.Time
+	warnedDuration bool
+	warnedIdle     bool
+}
+
+func newSessionWatchdog(limits profile.SessionLimits, now func() time.Time) *sessionWatchdog {
+	start := now()
+	return &sessionWatchdog{limits: limits, now: now, start: start, lastInput: start}
+}
+
+// touch resets the idle timeout
+func (w *sessionWatchdog) touch() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lastInput = w.now()
+	w.warnedIdle = false
+}
+
+// check returns a warning if a limit is about to be reached (only once per limit)
+// and the error of a reached limit
+func (w *sessionWatchdog) check() (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+
+	if maxDuration := w.limits.MaxDuration; maxDuration > 0 {
+		left := w.start.Add(maxDuration).Sub(now)
+		if left <= 0 {
+			return "", &sessionLimitError{limit: "max duration", value: maxDuration}
+		}
+		if !w.warnedDuration && left <= profile.WarnBefore(maxDuration) {
+			w.warnedDuration = true
+			return fmt.Sprintf("debug session ends in %s (max duration of %s)", left.Round(time.Second), maxDuration), nil
+		}
+	}
+
+	if idleTimeout := w.limits.IdleTimeout; idleTimeout > 0 {
+		left := w.lastInput.Add(idleTimeout).Sub(now)
+		if left <= 0 {
+			return "", &sessionLimitError{limit: "idle timeout", value: idleTimeout}
+		}
+		if !w.warnedIdle && left <= profile.WarnBefore(idleTimeout) {
+			w.warnedIdle = true
+			return fmt.Sprintf("debug session ends in %s without input (idle timeout of %s)", left.Round(time.Second), idleTimeout), nil
+		}
+	}
+
+	return "", nil
+}
+
+// run checks the limits every second until the context is done, warnings are written
+// to the raw terminal and a reached limit cancels the context with the limit as cause
+func (w *sessionWatchdog) run(ctx context.Context, cancel context.CancelCauseFunc, out io.Writer) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		warning, err := w.check()
+		if warning != "" {
+			fmt.Fprintf(out, "\r\n[dpm] warning: %s\r\n", warning)
+		}
+		if err != nil {
+			cancel(err)
+			return
+		}
+	}
+}
+
+// killSession kills the processes of the debug container, a failure only gets reported
+func killSession(ctx context.Context, namespace, podName, container string, streams genericiooptions.IOStreams) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), killTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	if err := execInContainer(ctx, namespace, podName, container, []string{"sh", "-c", killScript}, io.Discard, &stderr); err != nil {
+		fmt.Fprintf(streams.ErrOut, "warning: kill debug container %s: %v %s\n", container, err, strings.TrimSpace(stderr.String()))
+		return
+	}
+
+	fmt.Fprintf(streams.ErrOut, "killed the processes of debug container %s\n", container)
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func TestSessionWatchdog(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := start
+
+	w := newSessionWatchdog(profile.SessionLimits{MaxDuration: time.Hour, IdleTimeout: 10 * time.Minute}, func() time.Time { return clock })
+
+	steps := []struct {
+		at      time.Duration
+		input   bool
+		warning string
+		limit   string
+	}{
+		{at: time.Minute},
+		{at: 9*time.Minute + 30*time.Second, warning: "debug session ends in 30s without input (idle timeout of 10m0s)"},
+		{at: 9*time.Minute + 40*time.Second},
+		{at: 9*time.Minute + 50*time.Second, input: true},
+		{at: 50 * time.Minute, input: true},
+		{at: 59 * time.Minute, input: true, warning: "debug session ends in 1m0s (max duration of 1h0m0s)"},
+		{at: 59*time.Minute + 30*time.Second, input: true},
+		{at: time.Hour, limit: "max duration of 1h0m0s reached"},
+	}
+
+	for _, s := range steps {
+		clock = start.Add(s.at)
+		if s.input {
+			w.touch()
+		}
+
+		warning, err := w.check()
+		if warning != s.warning {
+			t.Errorf("check() at %s warning = %q, want %q", s.at, warning, s.warning)
+		}
+
+		var limitErr *sessionLimitError
+		switch {
+		case s.limit == "" && err != nil:
+			t.Errorf("check() at %s error = %v", s.at, err)
+		case s.limit != "" && (!errors.As(err, &limitErr) || err.Error() != s.limit):
+			t.Errorf("check() at %s error = %v, want %q", s.at, err, s.limit)
+		}
+	}
+
+	idle := newSessionWatchdog(profile.SessionLimits{IdleTimeout: time.Minute}, func() time.Time { return clock })
+	clock = clock.Add(time.Minute)
+	if _, err := idle.check(); err == nil || err.Error() != "idle timeout of 1m0s reached" {
+		t.Errorf("check() error = %v, want idle timeout", err)
+	}
+}
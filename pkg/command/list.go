@@ -32,12 +32,18 @@ func List() *cobra.Command {
 	}
 
 	listCmd.Flags().BoolVarP(&flagVerboseList, "wide", "w", false, "show more information about profiles")
+	listCmd.Flags().BoolVar(&flagAllContexts, allContextsFlagName, false, "show profiles of all kubeconfig contexts")
 
 	return listCmd
 }
 
 func generateListOutput(w io.Writer) error {
-	tbl := table.GenerateTable(profile.Config.Profiles, flagVerboseList)
+	profiles, err := scopedProfiles(profile.Config.Profiles)
+	if err != nil {
+		return fmt.Errorf("scope profiles to kubeconfig context: %w", err)
+	}
+
+	tbl := table.GenerateTable(profiles, flagVerboseList)
 	table.ConfigureStatic(&tbl)
 
 	if _, err := fmt.Fprintln(w, tbl.View()); err != nil {
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+// noPodsError reports that no pod of a namespace matches the selectors of the target
+type noPodsError struct {
+	namespace string
+	labels    string
+	fields    string
+}
+
+func (e *noPodsError) Error() string {
+	if e.fields != "" {
+		return fmt.Sprintf("no pods in namespace %s found with label selector %q and field selector %q", e.namespace, e.labels, e.fields)
+	}
+	return fmt.Sprintf("no pods in namespace %s found with label selector %s", e.namespace, e.labels)
+}
+
+// namespacePods are the target pods found in a namespace
+type namespacePods struct {
+	Namespace string
+	Pods      []string
+}
+
+// targetNamespaces returns the namespaces searched for the target pods, the namespaces
+// of the debug profile or its single target namespace
+func targetNamespaces(ctx context.Context, client kubernetes.Interface) ([]string, error) {
+	if !debugProfile.Namespaces.IsSet() {
+		return []string{getTargetNamespace()}, nil
+	}
+
+	return debugProfile.TargetNamespaces(ctx, client.CoreV1())
+}
+
+// searchNamespaces returns the target pods of every namespace, namespaces without
+// the target pod or without matching pods are left out
+func searchNamespaces(ctx context.Context, client kubernetes.Interface, namespaces []string, podName string, target *profile.WorkloadRef) ([]namespacePods, error) {
+	var found []namespacePods
+
+	for _, namespace := range namespaces {
+		if podName != "" {
+			_, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+			switch {
+			case apierrors.IsNotFound(err):
+				continue
+			case err != nil:
+				return nil, fmt.Errorf("get pod %q in namespace %q: %w", podName, namespace, err)
+			}
+			found = append(found, namespacePods{Namespace: namespace, Pods: []string{podName}})
+			continue
+		}
+
+		pods, err := getTargetPods(ctx, client, namespace, target)
+		var noPods *noPodsError
+		switch {
+		// the workload may only exist in some of the namespaces
+		case errors.As(err, &noPods) || apierrors.IsNotFound(err):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		found = append(found, namespacePods{Namespace: namespace, Pods: pods})
+	}
+
+	if len(found) == 0 {
+		what := "target pods"
+		switch {
+		case podName != "":
+			what = "pod " + podName
+		case target != nil:
+			what = "pods of " + target.String()
+		}
+		return nil, fmt.Errorf("no %s found in namespaces %s of profile %q", what, strings.Join(namespaces, ", "), debugProfile.ProfileName)
+	}
+
+	return found, nil
+}
+
+// resolveTarget returns the namespace and the name of the target pod and checks the
+// permissions for it. All namespaces of the debug profile are searched, if the target
+// pods are found in several of them the user picks one.
+func resolveTarget(ctx context.Context, podName string, target *profile.WorkloadRef, streams genericiooptions.IOStreams) (string, string, error) {
+	clientset, err := newClientset()
+	if err != nil {
+		return "", "", err
+	}
+
+	namespaces, err := targetNamespaces(ctx, clientset)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(namespaces) == 1 {
+		namespace := namespaces[0]
+
+		// check the permissions before kubectl fails in the middle of the session
+		if err := preflight(ctx, namespace, podName); err != nil {
+			return "", "", err
+		}
+
+		if podName == "" {
+			pods, err := getTargetPods(ctx, clientset, namespace, target)
+			if err != nil {
+				return "", "", fmt.Errorf("get target pod in namespace %q: %w", namespace, err)
+			}
+			podName = pods[len(pods)-1]
+		}
+
+		return namespace, podName, nil
+	}
+
+	found, err := searchNamespaces(ctx, clientset, namespaces, podName, target)
+	if err != nil {
+		return "", "", err
+	}
+
+	selected := found[0]
+	if len(found) > 1 {
+		if selected, err = pickNamespace(found, streams); err != nil {
+			return "", "", err
+		}
+	}
+
+	// same pod as with a single namespace
+	podName = selected.Pods[len(selected.Pods)-1]
+
+	if err := preflight(ctx, selected.Namespace, podName); err != nil {
+		return "", "", err
+	}
+
+	return selected.Namespace, podName, nil
+}
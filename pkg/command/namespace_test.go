@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func TestSearchNamespaces(t *testing.T) {
+	oldProfile := debugProfile
+	t.Cleanup(func() { debugProfile = oldProfile })
+
+	pod := func(namespace, name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: namespace, Labels: map[string]string{"app": "webapp"},
+		}}
+	}
+	client := fake.NewSimpleClientset(
+		pod("tenant-a", "webapp-1"),
+		pod("tenant-a", "webapp-2"),
+		pod("tenant-c", "webapp-1"),
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "webapp", Namespace: "tenant-c"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "webapp"}},
+			},
+		},
+	)
+	namespaces := []string{"tenant-a", "tenant-b", "tenant-c"}
+
+	tests := []struct {
+		name    string
+		profile profile.Profile
+		podName string
+		target  *profile.WorkloadRef
+		want    []namespacePods
+		wantErr bool
+	}{
+		{
+			name:    "matchLabels",
+			profile: profile.Profile{MatchLabels: map[string]string{"app": "webapp"}},
+			want: []namespacePods{
+				{Namespace: "tenant-a", Pods: []string{"webapp-1", "webapp-2"}},
+				{Namespace: "tenant-c", Pods: []string{"webapp-1"}},
+			},
+		},
+		{
+			name:    "pod name",
+			podName: "webapp-2",
+			want:    []namespacePods{{Namespace: "tenant-a", Pods: []string{"webapp-2"}}},
+		},
+		{
+			name:   "workload only in some namespaces",
+			target: &profile.WorkloadRef{Kind: profile.WorkloadKindDeployment, Name: "webapp"},
+			want:   []namespacePods{{Namespace: "tenant-c", Pods: []string{"webapp-1"}}},
+		},
+		{
+			name:    "no pods",
+			profile: profile.Profile{MatchLabels: map[string]string{"app": "other"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			debugProfile = tt.profile
+
+			got, err := searchNamespaces(context.Background(), client, namespaces, tt.podName, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("searchNamespaces() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("searchNamespaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
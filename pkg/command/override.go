@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+var pullPolicies = []corev1.PullPolicy{corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever}
+
+// explicitNamespace returns the namespace set by --namespace, empty if it isn't set
+func explicitNamespace() string {
+	if kubeConfigFlags == nil || kubeConfigFlags.Namespace == nil {
+		return ""
+	}
+	return *kubeConfigFlags.Namespace
+}
+
+// applyOverrides applies the flags of run overriding fields of the profile for a single
+// invocation, namespace is the explicitly set --namespace. A selector replaces the
+// workload and the label selector of the profile, its field selector still applies.
+func applyOverrides(p *profile.Profile, namespace string) error {
+	if flagImage != "" {
+		p.Image = flagImage
+	}
+
+	if namespace != "" {
+		p.Namespace = namespace
+		p.Namespaces = profile.NamespaceSelector{}
+	}
+
+	if flagSelector != "" {
+		selector, err := metav1.ParseToLabelSelector(flagSelector)
+		if err != nil {
+			return fmt.Errorf("invalid --selector %q: %w", flagSelector, err)
+		}
+
+		p.Workload = ""
+		p.MatchLabels = selector.MatchLabels
+		p.MatchExpressions = nil
+		for _, r := range selector.MatchExpressions {
+			p.MatchExpressions = append(p.MatchExpressions, profile.LabelRequirement{
+				Key:      r.Key,
+				Operator: string(r.Operator),
+				Values:   r.Values,
+			})
+		}
+	}
+
+	if flagTarget != "" {
+		p.TargetContainer = flagTarget
+	}
+
+	if flagPullPolicy != "" {
+		policy := corev1.PullPolicy(flagPullPolicy)
+		if !slices.Contains(pullPolicies, policy) {
+			return fmt.Errorf("invalid --pull-policy %q, must be one of Always, IfNotPresent or Never", flagPullPolicy)
+		}
+		p.ImagePullPolicy = policy
+	}
+
+	env, err := profile.ParseEnv(flagEnv)
+	if err != nil {
+		return fmt.Errorf("--env: %w", err)
+	}
+
+	set, err := profile.ParseSet(flagSet)
+	if err != nil {
+		return fmt.Errorf("--set: %w", err)
+	}
+
+	p.SetSpecOverrides(profile.SpecOverrides{Env: env, Set: set})
+
+	return nil
+}
+
+// printDryRun prints the effective values of the debug profile for the target pods
+func printDryRun(ctx context.Context, out io.Writer, pods []targetPod) error {
+	spec, err := debugProfile.Spec(ctx)
+	if err != nil {
+		return fmt.Errorf("get spec of profile %q: %w", debugProfile.ProfileName, err)
+	}
+
+	var namespaces, names []string
+	for _, pod := range pods {
+		if !slices.Contains(namespaces, pod.Namespace) {
+			namespaces = append(namespaces, pod.Namespace)
+		}
+		names = append(names, pod.ID)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "profile:\t%s\n", debugProfile.ProfileName)
+	fmt.Fprintf(w, "namespace:\t%s\n", strings.Join(namespaces, ","))
+	fmt.Fprintf(w, "pods:\t%s\n", strings.Join(names, ","))
+	fmt.Fprintf(w, "image:\t%s\n", debugProfile.Image)
+	fmt.Fprintf(w, "imagePullPolicy:\t%s\n", debugProfile.ImagePullPolicy)
+	fmt.Fprintf(w, "targetContainer:\t%s\n", valueOrNone(debugProfile.TargetContainer))
+	fmt.Fprintf(w, "selector:\t%s\n", valueOrNone(debugProfile.FormatSelector()))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, spec, "  ", "  "); err != nil {
+		return fmt.Errorf("format spec of profile %q: %w", debugProfile.ProfileName, err)
+	}
+
+	_, err = fmt.Fprintf(out, "spec:\n  %s\n", indented.Bytes())
+	return err
+}
+
+// valueOrNone returns "<none>" for empty values
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
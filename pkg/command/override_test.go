@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	base := profile.Profile{
+		ProfileName:     "webapp",
+		Image:           "nicolaka/netshoot",
+		Namespace:       "prod",
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Workload:        "deployment/webapp",
+		MatchLabels:     map[string]string{"app": "webapp"},
+		FieldSelector:   "status.phase=Running",
+	}
+
+	tests := []struct {
+		name       string
+		image      string
+		namespace  string
+		selector   string
+		target     string
+		pullPolicy string
+		env        []string
+		set        []string
+		check      func(t *testing.T, p profile.Profile)
+		wantErr    bool
+	}{
+		{
+			name: "no overrides",
+			check: func(t *testing.T, p profile.Profile) {
+				if p.Image != base.Image || p.Namespace != base.Namespace || p.SpecOverrides().IsSet() {
+					t.Errorf("profile changed without overrides: %+v", p)
+				}
+			},
+		},
+		{
+			name:       "container fields",
+			image:      "busybox:1.36",
+			target:     "app",
+			pullPolicy: "Always",
+			check: func(t *testing.T, p profile.Profile) {
+				if p.Image != "busybox:1.36" || p.TargetContainer != "app" || p.ImagePullPolicy != corev1.PullAlways {
+					t.Errorf("container fields not overridden: %+v", p)
+				}
+			},
+		},
+		{
+			name:      "namespace replaces namespaces",
+			namespace: "staging",
+			check: func(t *testing.T, p profile.Profile) {
+				if p.Namespace != "staging" || p.Namespaces.IsSet() {
+					t.Errorf("namespace not overridden: %+v", p)
+				}
+			},
+		},
+		{
+			name:     "selector replaces workload and labels",
+			selector: "app=api,track in (canary)",
+			check: func(t *testing.T, p profile.Profile) {
+				if got, want := p.FormatSelector(), "app=api,track in (canary); status.phase=Running"; got != want {
+					t.Errorf("FormatSelector() = %q, want %q", got, want)
+				}
+			},
+		},
+		{
+			name: "spec overrides",
+			env:  []string{"DEBUG=1"},
+			set:  []string{"spec.securityContext.privileged=true"},
+			check: func(t *testing.T, p profile.Profile) {
+				if o := p.SpecOverrides(); len(o.Env) != 1 || len(o.Set) != 1 {
+					t.Errorf("SpecOverrides() = %+v", o)
+				}
+			},
+		},
+		{name: "invalid pull policy", pullPolicy: "Sometimes", wantErr: true},
+		{name: "invalid selector", selector: "app in", wantErr: true},
+		{name: "invalid env", env: []string{"DEBUG"}, wantErr: true},
+		{name: "invalid set", set: []string{"image=busybox"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagImage, flagSelector, flagTarget, flagPullPolicy = tt.image, tt.selector, tt.target, tt.pullPolicy
+			flagEnv, flagSet = tt.env, tt.set
+			t.Cleanup(func() {
+				flagImage, flagSelector, flagTarget, flagPullPolicy = "", "", "", ""
+				flagEnv, flagSet = nil, nil
+			})
+
+			p := base
+			if tt.namespace != "" {
+				p.Namespace = ""
+				p.Namespaces = profile.NamespaceSelector{Names: []string{"tenant-*"}}
+			}
+
+			err := applyOverrides(&p, tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, p)
+			}
+		})
+	}
+}
+
+func TestContainerArgs(t *testing.T) {
+	debugProfile = profile.Profile{Image: "busybox", ImagePullPolicy: corev1.PullNever, TargetContainer: "app"}
+	t.Cleanup(func() { debugProfile = profile.Profile{} })
+
+	want := []string{"--container", "dpm-x", "--image", "busybox", "--image-pull-policy", "Never", "--target", "app"}
+	if got := containerArgs("dpm-x"); !slices.Equal(got, want) {
+		t.Errorf("containerArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestPrintDryRun(t *testing.T) {
+	debugProfile = profile.Profile{ProfileName: "net", Profile: "netadmin", Image: "busybox", ImagePullPolicy: corev1.PullIfNotPresent}
+	debugProfile.SetSpecOverrides(profile.SpecOverrides{Env: []corev1.EnvVar{{Name: "DEBUG", Value: "1"}}})
+	t.Cleanup(func() { debugProfile = profile.Profile{} })
+
+	var out bytes.Buffer
+	pods := []targetPod{{Namespace: "a", Name: "web-1", ID: "a/web-1"}, {Namespace: "b", Name: "web-2", ID: "b/web-2"}}
+	if err := printDryRun(context.Background(), &out, pods); err != nil {
+		t.Fatalf("printDryRun() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"namespace:        a,b",
+		"pods:             a/web-1,b/web-2",
+		"image:            busybox",
+		"targetContainer:  <none>",
+		`"name": "DEBUG"`,
+		`"NET_ADMIN"`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output misses %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func Policy() *cobra.Command {
+	policyCmd := &cobra.Command{
+		Use:   "policy",
+		Short: "test the policy debug sessions must satisfy",
+	}
+
+	policyCmd.AddCommand(policyTest())
+
+	return policyCmd
+}
+
+func policyTest() *cobra.Command {
+	var flagInput string
+
+	testCmd := &cobra.Command{
+		Use:   "test",
+		Short: "evaluate the policy against a recorded input",
+		Long: "evaluate the policy of the configuration file against an input without talking to the cluster. " +
+			"The input is a JSON object with the policy variables (profile, spec, pod and request), " +
+			"'kubectl dpm run --debug' prints the input of a real debug session. The command fails if the policy denies the input.",
+		Example: `  kubectl dpm policy test --input input.json`,
+		Args:    cobra.NoArgs,
+
+		RunE: func(c *cobra.Command, _ []string) error {
+			if err := config.GenerateConfig(); err != nil {
+				return fmt.Errorf("generate config: %w", err)
+			}
+
+			vars, err := readPolicyInput(flagInput)
+			if err != nil {
+				return err
+			}
+
+			rules, err := profile.PolicyRules()
+			if err != nil {
+				return err
+			}
+
+			policy, err := profile.CompilePolicy(rules)
+			if err != nil {
+				return fmt.Errorf("compile policy: %w", err)
+			}
+
+			decision, err := policy.Evaluate(vars)
+			if err != nil {
+				return err
+			}
+
+			c.SilenceUsage = true
+			if !decision.Allowed {
+				return fmt.Errorf("%w: %s", profile.ErrPolicyDenied, decision.Reason)
+			}
+
+			fmt.Fprintf(c.OutOrStdout(), "allowed by %d policy rule(s)\n", len(rules))
+			return nil
+		},
+	}
+
+	testCmd.Flags().StringVarP(&flagInput, "input", "f", "", "JSON file with the policy input")
+	_ = testCmd.MarkFlagRequired("input")
+
+	return testCmd
+}
+
+// readPolicyInput reads the policy variables from a JSON file,
+// missing variables are empty
+func readPolicyInput(file string) (map[string]any, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read policy input: %w", err)
+	}
+
+	vars := map[string]any{}
+	if err := json.Unmarshal(data, &vars); err != nil {
+		return nil, fmt.Errorf("parse policy input %q: %w", file, err)
+	}
+
+	for _, name := range []string{"profile", "spec", "pod", "request"} {
+		if vars[name] == nil {
+			vars[name] = map[string]any{}
+		}
+	}
+
+	return vars, nil
+}
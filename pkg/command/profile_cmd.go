@@ -0,0 +1,315 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func Profile() *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "add, edit, remove, rename, copy and generate profiles",
+		Long: "add, edit, remove, rename and copy profiles of the configuration file. " +
+			"Every change gets validated like 'kubectl dpm validate' before the configuration file is written.",
+	}
+
+	profileCmd.AddCommand(
+		profileAdd(),
+		profileEdit(),
+		profileRemove(),
+		profileRename(),
+		profileCopy(),
+		profileGenerate(),
+	)
+
+	return profileCmd
+}
+
+func profileAdd() *cobra.Command {
+	var (
+		p          profile.Profile
+		flagSource string
+		flagValue  string
+		flagGitRef string
+		flagGitDir string
+		flagPolicy string
+	)
+
+	addCmd := &cobra.Command{
+		Use:   "add NAME",
+		Short: "add a new profile",
+		Example: `  kubectl dpm profile add webapp --source file --value ~/.kube-dpm/profiles/webapp.json \
+    --image nicolaka/netshoot:v0.13 --namespace default --match-labels run=webapp
+  kubectl dpm profile add netadmin --source builtin --value netadmin
+  kubectl dpm profile add shared --source git --value https://github.com/org/profiles --git-path webapp.json`,
+		Args: cobra.ExactArgs(1),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			p.ProfileName = args[0]
+			p.ImagePullPolicy = corev1.PullPolicy(flagPolicy)
+			// the namespace is taken from the kubeconfig flag --namespace
+			p.Namespace = explicitNamespace()
+
+			switch flagSource {
+			case profile.SourceTypeFile:
+				p.ProfileSource = profile.ProfileSourceConfig{Type: flagSource, Path: flagValue}
+			case profile.SourceTypeBuiltIn:
+				p.ProfileSource = profile.ProfileSourceConfig{Type: flagSource, Name: flagValue}
+			case profile.SourceTypeGit:
+				p.ProfileSource = profile.ProfileSourceConfig{
+					Type: flagSource,
+					Git:  &profile.GitSourceConfig{URL: flagValue, Ref: flagGitRef, Path: flagGitDir},
+				}
+			case profile.SourceTypeConfigMap:
+				p.ProfileSource = profile.ProfileSourceConfig{
+					Type:      flagSource,
+					ConfigMap: &profile.ConfigMapSourceConfig{Name: flagValue},
+				}
+			default:
+				return fmt.Errorf("unknown profile source type: %q (valid types: file, git, configmap, builtin)", flagSource)
+			}
+
+			return changeConfig(c, func(doc *config.Document) error {
+				return doc.AddProfile(config.ProfileNode(&p))
+			})
+		},
+	}
+
+	flags := addCmd.Flags()
+	flags.StringVar(&flagSource, "source", profile.SourceTypeFile, "profile source type (file, builtin, git, configmap)")
+	flags.StringVar(&flagValue, "value", "", "path (file), profile name (builtin), repository URL (git) or ConfigMap name (configmap)")
+	flags.StringVar(&flagGitRef, "git-ref", "", "branch or tag of the git repository")
+	flags.StringVar(&flagGitDir, "git-path", "", "path of the profile within the git repository")
+	flags.StringVar(&p.Image, "image", "", "image of the debug container")
+	flags.StringSliceVar(&p.Namespaces.Names, "namespaces", nil, "namespaces searched for the target pod, shell patterns are allowed (e.g. tenant-*)")
+	flags.StringVar(&flagPolicy, "pull-policy", "", "image pull policy of the debug container")
+	flags.StringVar(&p.TargetContainer, "target", "", "target container")
+	flags.StringToStringVar(&p.MatchLabels, "match-labels", nil, "labels to find the target pod (e.g. app=web,tier=frontend)")
+	flags.StringVar(&p.FieldSelector, "field-selector", "", "field selector to find the target pod (e.g. status.phase=Running)")
+	flags.StringVar(&p.Workload, "workload", "", "workload of the target pod (e.g. deployment/webapp)")
+	flags.StringVar(&p.InheritEnvFrom, "inherit-env-from", "", "container of the target pod whose env and envFrom are copied into the debug container")
+	flags.StringSliceVar(&p.Contexts, "contexts", nil, "kubeconfig contexts the profile is limited to")
+	flags.StringSliceVar(&p.Clusters, "clusters", nil, "kubeconfig clusters the profile is limited to")
+
+	_ = addCmd.MarkFlagRequired("value")
+
+	return addCmd
+}
+
+func profileEdit() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit NAME",
+		Short: "edit a profile with $EDITOR",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			return editProfile(c, args[0])
+		},
+	}
+}
+
+func profileRemove() *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm NAME",
+		Aliases: []string{"remove"},
+		Short:   "remove a profile",
+		Args:    cobra.ExactArgs(1),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			return changeConfig(c, func(doc *config.Document) error {
+				return doc.RemoveProfile(args[0])
+			})
+		},
+	}
+}
+
+func profileRename() *cobra.Command {
+	return &cobra.Command{
+		Use:     "mv OLD_NAME NEW_NAME",
+		Aliases: []string{"rename"},
+		Short:   "rename a profile",
+		Args:    cobra.ExactArgs(2),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			return changeConfig(c, func(doc *config.Document) error {
+				return doc.RenameProfile(args[0], args[1])
+			})
+		},
+	}
+}
+
+func profileCopy() *cobra.Command {
+	return &cobra.Command{
+		Use:     "cp NAME NEW_NAME",
+		Aliases: []string{"copy"},
+		Short:   "copy a profile",
+		Args:    cobra.ExactArgs(2),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			return changeConfig(c, func(doc *config.Document) error {
+				return doc.CopyProfile(args[0], args[1])
+			})
+		},
+	}
+}
+
+// changeConfig loads the configuration file, applies change and writes the
+// configuration file back if the changed configuration is valid
+func changeConfig(c *cobra.Command, change func(doc *config.Document) error) error {
+	doc, err := config.LoadDocument(config.ConfigurationFile)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if err := change(doc); err != nil {
+		return err
+	}
+
+	if err := doc.Save(c.Context()); err != nil {
+		return fmt.Errorf("config not changed: %w", err)
+	}
+
+	return nil
+}
+
+// editProfile opens the profile in $EDITOR until it is valid or the user aborts by not changing it.
+// Like "kubectl edit", validation errors are shown as comments on top of the profile.
+func editProfile(c *cobra.Command, name string) error {
+	doc, err := config.LoadDocument(config.ConfigurationFile)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	node, err := doc.Profile(name)
+	if err != nil {
+		return err
+	}
+
+	content, err := yaml.Marshal(node)
+	if err != nil {
+		return fmt.Errorf("encode profile %q: %w", name, err)
+	}
+
+	for {
+		edited, err := runEditor(c, content)
+		if err != nil {
+			return err
+		}
+
+		edited = stripErrorComments(edited)
+		if len(bytes.TrimSpace(edited)) == 0 || bytes.Equal(edited, stripErrorComments(content)) {
+			return errors.New("edit cancelled, no changes made")
+		}
+
+		saveErr := saveEditedProfile(c, name, edited)
+		if saveErr == nil {
+			return nil
+		}
+
+		// show the error on top of the edited profile and re-open the editor
+		var header strings.Builder
+		header.WriteString(errorCommentPrefix + "Please edit the profile below. The profile will be re-opened as long as it is invalid.\n")
+		header.WriteString(errorCommentPrefix + "Save an unchanged or empty file to abort.\n")
+		for _, line := range strings.Split(saveErr.Error(), "\n") {
+			header.WriteString(errorCommentPrefix + line + "\n")
+		}
+		content = append([]byte(header.String()), edited...)
+	}
+}
+
+const errorCommentPrefix = "# dpm: "
+
+// saveEditedProfile replaces the profile of the unchanged configuration file with the
+// edited content and writes the configuration file back if it is valid
+func saveEditedProfile(c *cobra.Command, name string, edited []byte) error {
+	var editedDoc yaml.Node
+	if err := yaml.Unmarshal(edited, &editedDoc); err != nil {
+		return fmt.Errorf("parse profile: %w", err)
+	}
+
+	if editedDoc.Kind != yaml.DocumentNode || len(editedDoc.Content) != 1 || editedDoc.Content[0].Kind != yaml.MappingNode {
+		return errors.New("the profile must be a single YAML mapping")
+	}
+
+	// every attempt starts over from the configuration file
+	doc, err := config.LoadDocument(config.ConfigurationFile)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if err := doc.ReplaceProfile(name, editedDoc.Content[0]); err != nil {
+		return err
+	}
+
+	// validates the whole configuration, including ValidateProfile for the edited profile
+	return doc.Save(c.Context())
+}
+
+// stripErrorComments removes the error header added by editProfile
+func stripErrorComments(data []byte) []byte {
+	var out bytes.Buffer
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), errorCommentPrefix) {
+			continue
+		}
+		out.WriteString(scanner.Text() + "\n")
+	}
+
+	return out.Bytes()
+}
+
+// runEditor opens content in $EDITOR (or vi) and returns the saved content
+func runEditor(c *cobra.Command, content []byte) ([]byte, error) {
+	tmpFile, err := os.CreateTemp("", "kubectl-dpm-profile-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file for profile: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return nil, fmt.Errorf("write profile to temp file: %w", err)
+	}
+	tmpFile.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// EDITOR may contain arguments, e.g. "code --wait"
+	editorArgs := strings.Fields(editor)
+
+	// nolint:gosec
+	editorCmd := exec.Command(editorArgs[0], append(editorArgs[1:], tmpFile.Name())...)
+	editorCmd.Stdin = c.InOrStdin()
+	editorCmd.Stdout = c.OutOrStdout()
+	editorCmd.Stderr = c.ErrOrStderr()
+
+	if err := editorCmd.Run(); err != nil {
+		return nil, fmt.Errorf("run editor %q: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return nil, fmt.Errorf("read edited profile: %w", err)
+	}
+
+	return edited, nil
+}
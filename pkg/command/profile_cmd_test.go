@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+)
+
+const testProfileConfig = `profiles:
+  - name: netadmin
+    profileSource:
+      type: builtin
+      name: netadmin
+    image: nicolaka/netshoot:v0.13
+  - name: sysadmin
+    profileSource:
+      type: builtin
+      name: sysadmin
+    image: busybox:1.37
+`
+
+// runProfileCmd runs the profile command with args against a copy of testProfileConfig
+// and returns the configuration file afterwards
+func runProfileCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "debug-profiles.yaml")
+	if err := os.WriteFile(file, []byte(testProfileConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := config.ConfigurationFile
+	config.ConfigurationFile = file
+	t.Cleanup(func() { config.ConfigurationFile = previous })
+
+	root := Root()
+	root.AddCommand(Profile())
+	root.SetArgs(append([]string{"profile"}, args...))
+	root.SetOut(&strings.Builder{})
+	root.SetErr(&strings.Builder{})
+
+	err := root.Execute()
+
+	data, readErr := os.ReadFile(file)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	return string(data), err
+}
+
+func TestProfileCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant []string
+		wantErr bool
+	}{
+		{
+			name: "add",
+			args: []string{"add", "general", "--source", "builtin", "--value", "general", "--image", "busybox", "--namespace", "tools"},
+			want: []string{"- name: general", "namespace: tools"},
+		},
+		{name: "add existing", args: []string{"add", "netadmin", "--source", "builtin", "--value", "general"}, wantErr: true},
+		{name: "add invalid", args: []string{"add", "general", "--source", "builtin", "--value", "unknown"}, wantErr: true},
+		{name: "rm", args: []string{"rm", "sysadmin"}, notWant: []string{"sysadmin"}},
+		{name: "rm missing", args: []string{"rm", "webapp"}, wantErr: true},
+		{name: "mv", args: []string{"mv", "netadmin", "network"}, want: []string{"- name: network"}, notWant: []string{"- name: netadmin"}},
+		{name: "mv onto existing", args: []string{"mv", "netadmin", "sysadmin"}, wantErr: true},
+		{name: "mv missing", args: []string{"mv", "webapp", "network"}, wantErr: true},
+		{name: "cp", args: []string{"cp", "netadmin", "network"}, want: []string{"- name: netadmin", "- name: network"}},
+		{name: "cp onto existing", args: []string{"cp", "netadmin", "sysadmin"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runProfileCmd(t, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("profile %v error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if got != testProfileConfig {
+					t.Errorf("config changed on error:\n%s", got)
+				}
+				return
+			}
+
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("config is missing %q:\n%s", w, got)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("config still contains %q:\n%s", w, got)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileEdit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test editor is a shell script")
+	}
+
+	const valid = "name: netadmin\nprofileSource:\n  type: builtin\n  name: netadmin\nimage: busybox:1.36\n"
+	const invalid = "name: netadmin\nprofileSource:\n  type: builtin\n  name: unknown\nimage: busybox:1.37\n"
+
+	tests := []struct {
+		name        string
+		edits       []string
+		wantErr     bool
+		wantImage   string
+		wantHeaders int
+	}{
+		{name: "valid edit", edits: []string{valid}, wantImage: "busybox:1.36"},
+		{name: "retry after invalid edit", edits: []string{invalid, valid}, wantImage: "busybox:1.36", wantHeaders: 1},
+		{name: "abort after invalid edit", edits: []string{invalid, invalid}, wantErr: true, wantHeaders: 1},
+		{name: "unchanged", edits: []string{""}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the editor replaces the profile with the next non-empty edit and keeps the profile it was opened with
+			dir := t.TempDir()
+			editor := filepath.Join(dir, "editor.sh")
+			script := `#!/bin/sh
+n=$(($(cat "$0.count" 2>/dev/null || echo 0) + 1))
+echo $n > "$0.count"
+cp "$1" "$0.opened$n"
+if [ -f "$0.edit$n" ]; then cp "$0.edit$n" "$1"; fi
+`
+			if err := os.WriteFile(editor, []byte(script), 0o700); err != nil {
+				t.Fatal(err)
+			}
+			for i, edit := range tt.edits {
+				if edit == "" {
+					continue
+				}
+				if err := os.WriteFile(editor+".edit"+strconv.Itoa(i+1), []byte(edit), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv("EDITOR", editor)
+
+			got, err := runProfileCmd(t, "edit", "netadmin")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("profile edit error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && got != testProfileConfig {
+				t.Errorf("config changed on error:\n%s", got)
+			}
+			if tt.wantImage != "" && !strings.Contains(got, "image: "+tt.wantImage) {
+				t.Errorf("config is missing the edited image %q:\n%s", tt.wantImage, got)
+			}
+			if !strings.Contains(got, "- name: sysadmin") {
+				t.Errorf("config lost the other profiles:\n%s", got)
+			}
+
+			headers := 0
+			for i := range tt.edits {
+				opened, err := os.ReadFile(editor + ".opened" + strconv.Itoa(i+1))
+				if err != nil {
+					t.Fatalf("editor wasn't opened %d times: %v", len(tt.edits), err)
+				}
+				if strings.HasPrefix(string(opened), errorCommentPrefix) {
+					headers++
+				}
+			}
+			if headers != tt.wantHeaders {
+				t.Errorf("editor was opened %d times with validation errors, want %d", headers, tt.wantHeaders)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func profileGenerate() *cobra.Command {
+	var (
+		flagName      string
+		flagContainer string
+		flagImg       string
+		flagSpecDir   string
+		flagSave      bool
+		flagOverwrite bool
+		opts          profile.SkeletonOptions
+	)
+
+	generateCmd := &cobra.Command{
+		Use:   "generate [TYPE/]NAME",
+		Short: "generate a profile from a running workload",
+		Long: "generate a profile from a pod or workload. The custom profile spec mirrors the volumeMounts, " +
+			"env and envFrom (and with --security-context the securityContext) of the target container. " +
+			"matchLabels and namespace are taken from the selector of the owning workload.",
+		Example: `  kubectl dpm profile generate webapp-7d9c6b5f4-x2x9q
+  kubectl dpm profile generate deployment/webapp --container app --save
+  kubectl dpm profile generate statefulset/db --name db-debug --security-context --save`,
+		Args: cobra.ExactArgs(1),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			ref, err := profile.ParseWorkloadRef(args[0])
+			if err != nil {
+				return err
+			}
+
+			namespace, _, err := MatchVersionKubeConfigFlags.ToRawKubeConfigLoader().Namespace()
+			if err != nil {
+				return fmt.Errorf("get namespace: %w", err)
+			}
+
+			clientset, err := newClientset()
+			if err != nil {
+				return err
+			}
+
+			w, err := getWorkload(c.Context(), clientset, namespace, ref)
+			if err != nil {
+				return err
+			}
+
+			container, err := w.container(flagContainer)
+			if err != nil {
+				return err
+			}
+
+			name := flagName
+			if name == "" {
+				name = ref.Name
+			}
+
+			if flagSpecDir == "" {
+				flagSpecDir = filepath.Join(filepath.Dir(config.ConfigurationFile), "profiles")
+			}
+
+			skeleton, err := profile.NewSkeleton(ref.String(), name, namespace, w.Selector, container, flagSpecDir, opts)
+			if err != nil {
+				return err
+			}
+			skeleton.Profile.Image = flagImg
+
+			if !flagSave {
+				return printSkeleton(c.OutOrStdout(), &skeleton)
+			}
+
+			if err := saveSkeleton(c, &skeleton, flagOverwrite); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(c.ErrOrStderr(), "profile %q added, custom profile spec written to %s\n",
+				skeleton.Profile.ProfileName, skeleton.Profile.ProfileSource.Path)
+
+			return nil
+		},
+	}
+
+	flags := generateCmd.Flags()
+	flags.StringVar(&flagName, "name", "", "name of the generated profile (default: name of the workload)")
+	flags.StringVar(&flagContainer, "container", "", "container to mirror (default: default container of the pod)")
+	flags.StringVar(&flagImg, "image", defaultDebugImage, "image of the debug container")
+	flags.StringVar(&flagSpecDir, "spec-dir", "", "directory of the custom profile spec (default: profiles next to the config file)")
+	flags.BoolVar(&opts.Env, "env", true, "mirror env and envFrom of the container")
+	flags.BoolVar(&opts.SecurityContext, "security-context", false, "mirror the securityContext of the container")
+	flags.BoolVar(&flagSave, "save", false, "add the profile to the config file instead of printing it")
+	flags.BoolVar(&flagOverwrite, "overwrite", false, "overwrite an existing custom profile spec file")
+
+	return generateCmd
+}
+
+// printSkeleton prints the profile as config file entry followed by its custom profile spec
+func printSkeleton(out io.Writer, s *profile.Skeleton) error {
+	entry, err := yaml.Marshal(&yaml.Node{
+		Kind:    yaml.SequenceNode,
+		Content: []*yaml.Node{config.ProfileNode(&s.Profile)},
+	})
+	if err != nil {
+		return fmt.Errorf("encode profile %q: %w", s.Profile.ProfileName, err)
+	}
+
+	fmt.Fprintf(out, "# profile generated from %s\n%s", s.Origin, entry)
+	fmt.Fprintf(out, "---\n# custom profile spec %s\n%s\n", s.Profile.ProfileSource.Path, s.Spec)
+
+	return nil
+}
+
+// saveSkeleton writes the custom profile spec and adds the profile to the config file.
+// The spec file is removed again if the profile can't be added.
+func saveSkeleton(c *cobra.Command, s *profile.Skeleton, overwrite bool) error {
+	specPath := s.Profile.ProfileSource.Path
+
+	if _, err := os.Stat(specPath); err == nil && !overwrite {
+		return fmt.Errorf("custom profile spec %q already exists - use --overwrite to replace it", specPath)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("check custom profile spec %q: %w", specPath, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(specPath), 0o700); err != nil {
+		return fmt.Errorf("create profile directory: %w", err)
+	}
+
+	if err := os.WriteFile(specPath, s.Spec, 0o600); err != nil {
+		return fmt.Errorf("write profile spec %q: %w", specPath, err)
+	}
+
+	// the profile gets validated against the written spec
+	err := changeConfig(c, func(doc *config.Document) error {
+		return doc.AddProfile(config.ProfileNode(&s.Profile))
+	})
+	if err != nil && !overwrite {
+		_ = os.Remove(specPath)
+	}
+
+	return err
+}
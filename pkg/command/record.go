@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/kubectl/pkg/util/term"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/recording"
+)
+
+// recordingFile returns the file of the recording of a debug session
+func recordingFile(dir, profileName, podName string, now time.Time) string {
+	name := fmt.Sprintf("%s-%s-%s.cast", now.UTC().Format("20060102T150405Z"), profileName, strings.TrimPrefix(podName, "pod/"))
+	return filepath.Join(dir, strings.ReplaceAll(name, string(filepath.Separator), "-"))
+}
+
+// newSessionRecorder creates the recording file and writes the header, the file
+// needs to be closed after the session
+func newSessionRecorder(file, namespace, podName string, streams genericiooptions.IOStreams) (*recording.Recorder, *os.File, error) {
+	patterns, err := debugProfile.RedactPatterns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("profile %q: %w", debugProfile.ProfileName, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return nil, nil, fmt.Errorf("create recordings directory: %w", err)
+	}
+
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create recording: %w", err)
+	}
+
+	header := recording.Header{
+		Width:  80,
+		Height: 24,
+		Title:  fmt.Sprintf("kubectl dpm run -p %s %s/%s", debugProfile.ProfileName, namespace, podName),
+		Env:    map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	}
+	if size := (&term.TTY{Out: streams.Out}).GetSize(); size != nil {
+		header.Width, header.Height = int(size.Width), int(size.Height)
+	}
+
+	rec, err := recording.NewRecorder(f, header, recording.Options{
+		Input:  debugProfile.Record.Input,
+		Redact: patterns,
+	})
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return rec, f, nil
+}
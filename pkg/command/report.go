@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+// reportWriter writes a validation report in one output format
+type reportWriter func(out io.Writer, report *profile.Report, strict bool) error
+
+var reportWriters = map[string]reportWriter{
+	"text":  writeTextReport,
+	"json":  writeJSONReport,
+	"junit": writeJUnitReport,
+}
+
+// configTestName is the name findings without a profile are reported under
+const configTestName = "config"
+
+// reportGroups returns the names of all groups of findings: the profiles and
+// (if there are findings without a profile) the config file itself
+func reportGroups(report *profile.Report) []string {
+	groups := report.Profiles
+	if len(report.ProfileFindings("")) > 0 {
+		groups = append([]string{""}, groups...)
+	}
+	return groups
+}
+
+func groupName(group string) string {
+	if group == "" {
+		return configTestName
+	}
+	return group
+}
+
+// groupFailed reports whether the findings of a group fail the validation
+func groupFailed(findings []profile.Finding, strict bool) bool {
+	r := profile.Report{Findings: findings}
+	return r.Failed(strict)
+}
+
+func writeTextReport(out io.Writer, report *profile.Report, strict bool) error {
+	// align the fields of all findings, not only within a profile
+	fieldWidth := 0
+	for _, f := range report.Findings {
+		fieldWidth = max(fieldWidth, len(f.Field))
+	}
+
+	for _, group := range reportGroups(report) {
+		r := profile.Report{Findings: report.ProfileFindings(group)}
+
+		status := "PASS"
+		switch {
+		case r.Failed(strict):
+			status = "FAIL"
+		case r.Count(profile.SeverityWarning) > 0:
+			status = "WARN"
+		}
+
+		fmt.Fprintf(out, "%s %s\n", status, groupName(group))
+
+		for _, f := range r.Findings {
+			fmt.Fprintf(out, "  %-7s  %-*s  %s\n", f.Severity, fieldWidth, f.Field, f.Message)
+		}
+	}
+
+	_, err := fmt.Fprintf(out, "\n%d profile(s), %d error(s), %d warning(s)\n",
+		len(report.Profiles), report.Count(profile.SeverityError), report.Count(profile.SeverityWarning))
+
+	return err
+}
+
+func writeJSONReport(out io.Writer, report *profile.Report, strict bool) error {
+	findings := report.Findings
+	if findings == nil {
+		findings = []profile.Finding{}
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(struct {
+		Failed   bool              `json:"failed"`
+		Strict   bool              `json:"strict"`
+		Profiles []string          `json:"profiles"`
+		Findings []profile.Finding `json:"findings"`
+	}{
+		Failed:   report.Failed(strict),
+		Strict:   strict,
+		Profiles: report.Profiles,
+		Findings: findings,
+	})
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnitReport writes one test case per profile, failing findings become the failure
+// of the test case and all other findings are written to system-out
+func writeJUnitReport(out io.Writer, report *profile.Report, strict bool) error {
+	suite := junitTestSuite{Name: "profiles"}
+
+	for _, group := range reportGroups(report) {
+		findings := report.ProfileFindings(group)
+		tc := junitTestCase{Name: groupName(group), ClassName: "kubectl-dpm.validate"}
+
+		var failures, other []string
+		for _, f := range findings {
+			line := fmt.Sprintf("%s: %s", f.Severity, f.Message)
+			if f.Field != "" {
+				line = fmt.Sprintf("%s: %s: %s", f.Severity, f.Field, f.Message)
+			}
+
+			if f.Severity == profile.SeverityError || (strict && f.Severity == profile.SeverityWarning) {
+				failures = append(failures, line)
+			} else {
+				other = append(other, line)
+			}
+		}
+
+		if groupFailed(findings, strict) {
+			tc.Failure = &junitFailure{
+				Message: failures[0],
+				Type:    "validation",
+				Text:    strings.Join(failures, "\n"),
+			}
+			suite.Failures++
+		}
+		tc.SystemOut = strings.Join(other, "\n")
+
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	suite.Tests = len(suite.TestCases)
+
+	suites := junitTestSuites{
+		Name:     "kubectl dpm validate",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(out, "\n")
+	return err
+}
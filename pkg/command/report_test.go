@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func testReport() *profile.Report {
+	return &profile.Report{
+		Profiles: []string{"git", "legacy", "webapp"},
+		Findings: []profile.Finding{
+			{Severity: profile.SeverityError, Profile: "git", Field: profile.FieldSpec, Message: "clone repository: not found"},
+			{Severity: profile.SeverityWarning, Profile: "legacy", Field: "profile", Message: "the profile field is deprecated"},
+		},
+	}
+}
+
+func TestWriteTextReport(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeTextReport(&out, testReport(), false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"FAIL git\n",
+		"WARN legacy\n",
+		"PASS webapp\n",
+		"3 profile(s), 1 error(s), 1 warning(s)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("writeTextReport() = %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestWriteJSONReport(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeJSONReport(&out, testReport(), true); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Failed   bool              `json:"failed"`
+		Findings []profile.Finding `json:"findings"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("writeJSONReport() wrote invalid JSON: %v", err)
+	}
+
+	if !got.Failed || len(got.Findings) != 2 || got.Findings[0].Profile != "git" {
+		t.Errorf("writeJSONReport() = %s", out.String())
+	}
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	tests := []struct {
+		name         string
+		strict       bool
+		wantFailures int
+	}{
+		{name: "warnings pass", wantFailures: 1},
+		{name: "strict mode fails on warnings", strict: true, wantFailures: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeJUnitReport(&out, testReport(), tt.strict); err != nil {
+				t.Fatal(err)
+			}
+
+			var got junitTestSuites
+			if err := xml.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("writeJUnitReport() wrote invalid XML: %v", err)
+			}
+
+			if got.Tests != 3 || got.Failures != tt.wantFailures {
+				t.Errorf("writeJUnitReport() tests = %d, failures = %d, want 3 and %d", got.Tests, got.Failures, tt.wantFailures)
+			}
+		})
+	}
+}
@@ -4,13 +4,36 @@ package command
 
 import (
 	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+var (
+	MatchVersionKubeConfigFlags *cmdutil.MatchVersionFlags
+	kubeConfigFlags             *genericclioptions.ConfigFlags
 )
 
 func Root() *cobra.Command {
-	return &cobra.Command{
+	root := &cobra.Command{
 		Use:           "kubectl-dpm",
 		Short:         "kubectl debug profile manager",
 		SilenceUsage:  false,
 		SilenceErrors: false,
+
+		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+			profile.Debug = flagDebug
+		},
 	}
+
+	root.PersistentFlags().BoolVarP(&flagDebug, "debug", "d", false, "print debug information")
+
+	// add kubeconfig flags, they are needed by every sub command which
+	// talks to the cluster or scopes profiles to the active context
+	kubeConfigFlags = genericclioptions.NewConfigFlags(true)
+	MatchVersionKubeConfigFlags = cmdutil.NewMatchVersionFlags(kubeConfigFlags)
+	kubeConfigFlags.AddFlags(root.PersistentFlags())
+
+	return root
 }
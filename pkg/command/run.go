@@ -4,27 +4,29 @@ package command
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
-	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 
+	"github.com/bavarianbidi/kubectl-dpm/pkg/audit"
 	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
 	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
 )
 
-var (
-	MatchVersionKubeConfigFlags *cmdutil.MatchVersionFlags
-	debugProfile                profile.Profile
-)
+var debugProfile profile.Profile
 
 func NewCmdDebugProfile(streams genericiooptions.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
@@ -32,16 +34,54 @@ func NewCmdDebugProfile(streams genericiooptions.IOStreams) *cobra.Command {
 		// DisableFlagsInUseLine: true,
 		Short: "create an ephemeral debug container in a pod",
 		Long:  "create an ephemeral debug container in a pod by using the kubectl debug implementation and a custom profile",
-		// at most one argument is allowed, which is the target pod name. If no argument is provided, the plugin will try to find a target pod based on the profile's matchLabels and use the first container in that pod as target.
-		Args: cobra.MaximumNArgs(1),
+		Example: `  kubectl dpm run -p webapp webapp-7d9c6b5f4-x2x9q
+  kubectl dpm run -p webapp deployment/webapp
+  kubectl dpm run -p webapp -n staging --image busybox:1.36 --env DEBUG=1 --dry-run
+  kubectl dpm run --spec ./my.json --image busybox pod/webapp-7d9c6b5f4-x2x9q
+  kubectl dpm run --source git+https://github.com/org/profiles#netadmin.json@v1.0.0 --image busybox deployment/webapp
+  kubectl dpm run -p netadmin --all --parallel 5 --output-dir ./out -- ss -tlnp`,
+		// at most one argument is allowed, which is the target pod name or a workload like deployment/webapp.
+		// If no argument is provided, the plugin will try to find a target pod based on the profile's workload
+		// and matchLabels and use the first container in that pod as target.
+		// The arguments after -- are the command of the debug container, which is only supported with --all.
+		Args: func(c *cobra.Command, args []string) error {
+			targets, command := splitCommand(c, args)
+			switch {
+			case !flagAll && len(command) > 0:
+				return fmt.Errorf("a command is only supported with --all")
+			case len(targets) > 1:
+				return fmt.Errorf("accepts at most 1 pod or workload, received %d", len(targets))
+			}
+			return nil
+		},
 
 		RunE: func(c *cobra.Command, args []string) error {
+			adHoc := c.Flags().Changed(specFlagName) || c.Flags().Changed(sourceFlagName)
+
 			if err := config.GenerateConfig(); err != nil {
-				return fmt.Errorf("generate config: %w", err)
+				// ad-hoc profiles don't need a configuration file
+				if !adHoc || !errors.Is(err, fs.ErrNotExist) {
+					return fmt.Errorf("generate config: %w", err)
+				}
+			}
+
+			if adHoc {
+				if err := selectAdHocProfile(); err != nil {
+					return err
+				}
+			}
+			profileSelected := adHoc || c.Flags().Changed(profileFlagName)
+
+			if flagAll {
+				if !profileSelected {
+					return fmt.Errorf("--all is non-interactive and needs --%s", profileFlagName)
+				}
+				targets, command := splitCommand(c, args)
+				return runAll(c.Context(), targets, command, streams)
 			}
 
 			// if no profile flag is set, start interactive mode to select a profile
-			if !c.Flags().Changed(profileFlagName) {
+			if !profileSelected {
 				model, err := initTeaModel(c.Context())
 				if err != nil {
 					return fmt.Errorf("run debug command: %w", err)
@@ -64,23 +104,153 @@ func NewCmdDebugProfile(streams genericiooptions.IOStreams) *cobra.Command {
 		},
 	}
 
-	// define flags
-	flags := cmd.PersistentFlags()
-
-	// add kubeconfig flags
-	kubeConfigFlags := genericclioptions.NewConfigFlags(true)
-	MatchVersionKubeConfigFlags = cmdutil.NewMatchVersionFlags(kubeConfigFlags)
-	kubeConfigFlags.AddFlags(flags)
-
 	// add custom flag
 	cmd.Flags().StringVarP(&flagProfileName, profileFlagName, "p", "", "profile name")
-	cmd.Flags().StringVarP(&flagImage, "image", "i", "", "image to use for the debug container")
-	cmd.Flags().BoolVarP(&flagDebug, "debug", "d", false, "print debug information")
+	cmd.Flags().StringVarP(&flagImage, "image", "i", "", "image of the debug container, overrides the image of the profile")
+	cmd.Flags().StringVarP(&flagSelector, "selector", "l", "", "label selector of the target pods, overrides the workload and the label selector of the profile")
+	cmd.Flags().StringVar(&flagTarget, "target", "", "target container, overrides the target container of the profile")
+	cmd.Flags().StringVar(&flagPullPolicy, "pull-policy", "", "image pull policy of the debug container (Always, IfNotPresent or Never)")
+	cmd.Flags().StringArrayVar(&flagEnv, "env", nil, "environment variable KEY=VALUE of the debug container, can be repeated")
+	cmd.Flags().StringArrayVar(&flagSet, "set", nil, "set a field of the profile spec, e.g. spec.securityContext.privileged=true, can be repeated")
+	cmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "print the effective profile and the target pods without running it")
+	cmd.Flags().StringVar(&flagAdHocSpec, specFlagName, "", "run the profile spec of a JSON file which isn't in the configuration, needs --image")
+	cmd.Flags().StringVar(&flagAdHocSource, sourceFlagName, "", "run a profile source which isn't in the configuration (git+URL#PATH[@REF], builtin:NAME, configmap:NAME or file:PATH), needs --image")
+	cmd.MarkFlagsMutuallyExclusive(profileFlagName, specFlagName, sourceFlagName)
+	cmd.Flags().BoolVar(&flagAllContexts, allContextsFlagName, false, "offer profiles of all kubeconfig contexts in interactive mode")
+	cmd.Flags().BoolVar(&flagForce, "force", false, "run a profile even if it is not scoped to the current kubeconfig context")
+	cmd.Flags().BoolVar(&flagAllowRisky, "allow-risky", false, "run a profile even if it violates lint rules with severity error")
+	cmd.Flags().BoolVar(&flagRecord, "record", false, "record the debug session as asciicast v2 (replay with 'kubectl dpm sessions replay')")
+	cmd.Flags().BoolVar(&flagAll, "all", false, "run the profile non-interactively in all pods of the workload or matching the profile")
+	cmd.Flags().IntVar(&flagParallel, "parallel", 1, "number of pods debugged at the same time with --all")
+	cmd.Flags().StringVar(&flagAllOutputDir, "output-dir", "", "also write the output of every pod to <dir>/<pod>.log with --all")
 
 	return cmd
 }
 
 func run(ctx context.Context, args []string, streams genericiooptions.IOStreams) error {
+	targetPodName, target, err := parseTarget(args)
+	if err != nil {
+		return err
+	}
+
+	if err := prepareRun(ctx); err != nil {
+		return err
+	}
+
+	namespace, targetPodName, err := resolveTarget(ctx, targetPodName, target, streams)
+	if err != nil {
+		return err
+	}
+
+	// lint and policy rules check without the target pod if it can't be fetched, inheritEnvFrom fails without it
+	pod := fetchPod(ctx, namespace, targetPodName)
+
+	if err := debugProfile.InheritEnv(pod); err != nil {
+		return err
+	}
+
+	if err := lintBeforeRun(ctx, pod, streams); err != nil {
+		return err
+	}
+
+	if flagDryRun {
+		return printDryRun(ctx, streams.Out, []targetPod{{Namespace: namespace, Name: targetPodName, ID: targetPodName}})
+	}
+
+	auditEntry := newAuditEntry(ctx, namespace, targetPodName)
+
+	if err := checkPolicy(ctx, namespace, pod, auditEntry, streams); err != nil {
+		return err
+	}
+
+	record, err := debugProfile.ShouldRecord(flagRecord)
+	if err != nil {
+		return err
+	}
+
+	limits := debugProfile.SessionLimits()
+
+	if flagDebug {
+		fmt.Fprintf(streams.Out, "Using profile: %+v\n", debugProfile)
+		fmt.Fprintf(streams.Out, "kubectl path: %s\n", os.ExpandEnv(profile.Config.KubectlPath))
+		if debugProfile.GetSource() != nil {
+			fmt.Fprintf(streams.Out, "profile source type: %s\n", debugProfile.GetSource().Type())
+		} else {
+			fmt.Fprintf(streams.Out, "profile path (legacy): %s\n", debugProfile.Profile)
+			fmt.Fprintf(streams.Out, "profile path resolved (legacy): %s\n", os.ExpandEnv(debugProfile.Profile))
+		}
+	}
+
+	profileArgs, cleanup, err := kubectlProfileArgs(ctx, streams)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// the name prefix tags the ephemeral container as created by dpm
+	sessionContainer := profile.SessionContainerName(debugProfile.ProfileName)
+	auditEntry.Container = sessionContainer
+
+	if profile.Config.Sessions.Annotate {
+		annotateSession(ctx, namespace, targetPodName, sessionContainer, auditEntry, streams)
+	}
+
+	debugArgs := append([]string{"debug", "--namespace", namespace}, profileArgs...)
+	debugArgs = append(debugArgs, containerArgs(sessionContainer)...)
+	debugArgs = append(debugArgs, targetPodName, "-it")
+
+	// recorded and limited debug sessions are attached by dpm to see the terminal
+	nativeAttach := record || limits.Active()
+	if nativeAttach {
+		debugArgs = append(debugArgs, "--attach=false")
+	}
+
+	debugCommand := newDebugCommand(debugArgs)
+
+	debugCommand.Stdout = streams.Out
+	debugCommand.Stderr = streams.ErrOut
+	if !nativeAttach {
+		debugCommand.Stdin = streams.In
+	}
+
+	if flagDebug {
+		fmt.Fprintf(streams.Out, "Running command: %s\n", debugCommand.String())
+	}
+
+	auditEntry.Timestamp = time.Now()
+	err = debugCommand.Run()
+	if err == nil && nativeAttach {
+		file := ""
+		if record {
+			file = recordingFile(profile.RecordingsDir(), debugProfile.ProfileName, targetPodName, auditEntry.Timestamp)
+		}
+		err = attachSession(ctx, namespace, targetPodName, sessionContainer, file, limits, streams)
+		if _, statErr := os.Stat(file); file != "" && statErr == nil {
+			auditEntry.Recording = file
+			fmt.Fprintf(streams.ErrOut, "debug session recorded to %s\n", file)
+		}
+	}
+	auditEntry.Duration = time.Since(auditEntry.Timestamp).Seconds()
+	if len(debugProfile.Collect) > 0 {
+		auditEntry.Artifacts = collectAfterRun(ctx, namespace, targetPodName, sessionContainer, streams)
+	}
+	auditEntry.ExitCode = exitCode(err)
+	if err != nil {
+		auditEntry.Error = err.Error()
+	}
+	recordAudit(auditEntry, streams)
+
+	if err != nil {
+		return fmt.Errorf("validate profile %q: %w", flagProfileName, err)
+	}
+
+	return nil
+}
+
+// prepareRun selects, validates and checks the debug profile and applies the overrides
+// of the command line, the target namespace and
+// the permissions are checked by resolveTarget
+func prepareRun(ctx context.Context) error {
 	// validate kubectl path
 	if err := profile.ValidateKubectlPath(); err != nil {
 		return fmt.Errorf("run debug profile: %w", err)
@@ -109,6 +279,18 @@ func run(ctx context.Context, args []string, streams genericiooptions.IOStreams)
 
 	debugProfile = profile.Config.Profiles[idx]
 
+	// refuse profiles which are scoped to other kubeconfig contexts
+	if !flagForce {
+		kubeContext, err := currentKubeContext()
+		if err != nil {
+			return fmt.Errorf("get current kubeconfig context: %w", err)
+		}
+		if !debugProfile.MatchesContext(kubeContext) {
+			return fmt.Errorf("profile %q is not available in kubeconfig context %q (cluster %q) - use --force to run it anyway",
+				flagProfileName, kubeContext.Name, kubeContext.Cluster)
+		}
+	}
+
 	// For ConfigMap sources, we need to inject a Kubernetes client
 	if debugProfile.ProfileSource.Type == profile.SourceTypeConfigMap && debugProfile.GetSource() == nil {
 		restClient, err := MatchVersionKubeConfigFlags.ToRESTConfig()
@@ -128,164 +310,247 @@ func run(ctx context.Context, args []string, streams genericiooptions.IOStreams)
 		profile.Config.Profiles[idx] = debugProfile
 	}
 
-	var targetContainer string
-	namespace := getTargetNamespace()
+	// the overrides of the command line only apply to this invocation
+	if err := applyOverrides(&debugProfile, explicitNamespace()); err != nil {
+		return fmt.Errorf("override profile %q: %w", flagProfileName, err)
+	}
+
+	// the image is optional in the config, without it kubectl debug can't create the debug container
+	if debugProfile.Image == "" {
+		return fmt.Errorf("profile %q has no image - set image in the config or use --image", flagProfileName)
+	}
+
+	return nil
+}
+
+// kubectlProfileArgs returns the --profile or --custom flag of kubectl debug for the
+// debug profile, cleanup removes the temporary spec file of custom profiles.
+// Run time changes of the spec of built-in profiles are passed as --custom in addition to --profile.
+func kubectlProfileArgs(ctx context.Context, streams genericiooptions.IOStreams) ([]string, func(), error) {
+
+	builtIn := ""
+	if source := debugProfile.GetSource(); source != nil && source.Type() == profile.SourceTypeBuiltIn {
+		// Built-in profile - use --profile flag with the profile name
+		builtInSource, ok := source.(*profile.BuiltInProfileSource)
+		if !ok {
+			return nil, nil, fmt.Errorf("internal error: built-in source type assertion failed")
+		}
+		builtIn = builtInSource.ProfileName()
+	} else if source == nil && debugProfile.IsBuiltInProfile() {
+		// Legacy profile field
+		builtIn = debugProfile.Profile
+	}
 
 	switch {
-	case len(args) == 1:
-		targetContainer = args[0]
-	case len(debugProfile.MatchLabels) > 0:
-		var err error
-		targetContainer, err = getTargetPod(ctx, namespace)
+	case builtIn != "" && !debugProfile.SpecPatched():
+		return []string{"--profile", builtIn}, func() {}, nil
+	case builtIn != "":
+		spec, err := debugProfile.PatchSpec(nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("patch spec of profile %q: %w", debugProfile.ProfileName, err)
+		}
+		file, cleanup, err := writeSpecFile(spec, streams)
 		if err != nil {
-			return fmt.Errorf("get target pod in namespace %q: %w", namespace, err)
+			return nil, nil, err
 		}
-	default:
-		return fmt.Errorf("no target container specified")
+		return []string{"--profile", builtIn, "--custom", file}, cleanup, nil
+	case debugProfile.GetSource() == nil && !debugProfile.SpecPatched():
+		// Legacy profile field
+		return []string{"--custom", os.ExpandEnv(debugProfile.Profile)}, func() {}, nil
 	}
 
-	if flagDebug {
-		fmt.Fprintf(streams.Out, "Using profile: %+v\n", debugProfile)
-		fmt.Fprintf(streams.Out, "kubectl path: %s\n", os.ExpandEnv(profile.Config.KubectlPath))
-		if debugProfile.GetSource() != nil {
-			fmt.Fprintf(streams.Out, "profile source type: %s\n", debugProfile.GetSource().Type())
-		} else {
-			fmt.Fprintf(streams.Out, "profile path (legacy): %s\n", debugProfile.Profile)
-			fmt.Fprintf(streams.Out, "profile path resolved (legacy): %s\n", os.ExpandEnv(debugProfile.Profile))
-		}
+	// Custom profile - fetch spec and write to temp file
+	spec, err := debugProfile.Spec(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch spec of profile %q: %w", debugProfile.ProfileName, err)
 	}
 
-	var debugCommand *exec.Cmd
+	file, cleanup, err := writeSpecFile(spec, streams)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Use ProfileSource if available, otherwise fall back to legacy Profile field
-	if debugProfile.GetSource() != nil {
-		source := debugProfile.GetSource()
+	return []string{"--custom", file}, cleanup, nil
+}
 
-		if source.Type() == profile.SourceTypeBuiltIn {
-			// Built-in profile - use --profile flag with the profile name
-			builtInSource, ok := source.(*profile.BuiltInProfileSource)
-			if !ok {
-				return fmt.Errorf("internal error: built-in source type assertion failed")
-			}
+// writeSpecFile writes the profile spec to a temp file, cleanup removes it
+func writeSpecFile(spec []byte, streams genericiooptions.IOStreams) (string, func(), error) {
+	tmpFile, err := os.CreateTemp("", "kubectl-dpm-profile-*.json")
+	if err != nil {
+		return "", nil, fmt.Errorf("create temp file for profile spec: %w", err)
+	}
+	cleanup := func() { os.Remove(tmpFile.Name()) }
 
-			// nolint:gosec
-			debugCommand = exec.Command(
-				os.ExpandEnv(profile.Config.KubectlPath),
-				"debug",
-				"--namespace", namespace,
-				"--profile", builtInSource.ProfileName(),
-				"--image", debugProfile.Image, targetContainer,
-				"-it",
-			)
-		} else {
-			// Custom profile - fetch spec and write to temp file
-			specData, err := source.GetSpec(ctx)
-			if err != nil {
-				return fmt.Errorf("fetch profile spec from %s source: %w", source.Type(), err)
-			}
+	if _, err := tmpFile.Write(spec); err != nil {
+		tmpFile.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("write profile spec to temp file: %w", err)
+	}
+	tmpFile.Close()
 
-			// Create temp file for the profile spec
-			tmpFile, err := os.CreateTemp("", "kubectl-dpm-profile-*.json")
-			if err != nil {
-				return fmt.Errorf("create temp file for profile spec: %w", err)
-			}
-			defer os.Remove(tmpFile.Name())
+	if flagDebug {
+		fmt.Fprintf(streams.Out, "profile spec written to temp file: %s\n", tmpFile.Name())
+	}
 
-			if _, err := tmpFile.Write(specData); err != nil {
-				tmpFile.Close()
-				return fmt.Errorf("write profile spec to temp file: %w", err)
-			}
-			tmpFile.Close()
+	return tmpFile.Name(), cleanup, nil
+}
 
-			if flagDebug {
-				fmt.Fprintf(streams.Out, "profile spec written to temp file: %s\n", tmpFile.Name())
-			}
+// containerArgs returns the flags of kubectl debug configuring the debug container
+func containerArgs(sessionContainer string) []string {
+	args := []string{"--container", sessionContainer, "--image", debugProfile.Image}
 
-			// nolint:gosec
-			debugCommand = exec.Command(
-				os.ExpandEnv(profile.Config.KubectlPath),
-				"debug",
-				"--namespace", namespace,
-				"--custom", tmpFile.Name(),
-				"--image", debugProfile.Image, targetContainer,
-				"-it",
-			)
-		}
-	} else {
-		// Legacy profile field
-		switch {
-		case debugProfile.IsBuiltInProfile():
-			// nolint:gosec
-			debugCommand = exec.Command(
-				os.ExpandEnv(profile.Config.KubectlPath),
-				"debug",
-				"--namespace", namespace,
-				"--profile", debugProfile.Profile,
-				"--image", debugProfile.Image, targetContainer,
-				"-it",
-			)
-		default:
-			// nolint:gosec
-			debugCommand = exec.Command(
-				os.ExpandEnv(profile.Config.KubectlPath),
-				"debug",
-				"--namespace", namespace,
-				"--custom", os.ExpandEnv(debugProfile.Profile),
-				"--image", debugProfile.Image, targetContainer,
-				"-it",
-			)
-		}
+	if debugProfile.ImagePullPolicy != "" {
+		args = append(args, "--image-pull-policy", string(debugProfile.ImagePullPolicy))
+	}
+	if debugProfile.TargetContainer != "" {
+		args = append(args, "--target", debugProfile.TargetContainer)
 	}
 
+	return args
+}
+
+// newDebugCommand returns the kubectl command with the arguments
+func newDebugCommand(debugArgs []string) *exec.Cmd {
+	// nolint:gosec
+	debugCommand := exec.Command(os.ExpandEnv(profile.Config.KubectlPath), debugArgs...)
+
 	debugCommand.Env = os.Environ()
 	// kubectl feature flag DebugCustomProfile got dropped in 1.34
 	// explicitly set it to true to support kubectl versions < 1.34
 	debugCommand.Env = append(debugCommand.Env, string("KUBECTL_DEBUG_CUSTOM_PROFILE=true"))
 
-	debugCommand.Stdout = streams.Out
-	debugCommand.Stderr = streams.ErrOut
-	debugCommand.Stdin = streams.In
+	return debugCommand
+}
 
-	if flagDebug {
-		fmt.Fprintf(streams.Out, "Running command: %s\n", debugCommand.String())
+// preflight checks that the user has all permissions needed to debug a pod with the debug profile
+func preflight(ctx context.Context, namespace, podName string) error {
+	clientset, err := newClientset()
+	if err != nil {
+		return err
 	}
 
-	if err := debugCommand.Run(); err != nil {
-		return fmt.Errorf("validate profile %q: %w", flagProfileName, err)
+	if err := profile.Preflight(ctx, clientset.AuthorizationV1(), &debugProfile, namespace, podName); err != nil {
+		return fmt.Errorf("preflight of profile %q: %w", debugProfile.ProfileName, err)
 	}
 
 	return nil
 }
 
-func getTargetPod(ctx context.Context, namespace string) (string, error) {
-	restClient, err := MatchVersionKubeConfigFlags.ToRESTConfig()
+// fetchPod returns the pod or nil if it can't be fetched
+func fetchPod(ctx context.Context, namespace, podName string) *corev1.Pod {
+	restConfig, err := MatchVersionKubeConfigFlags.ToRESTConfig()
+	if err != nil {
+		return nil
+	}
+
+	client, err := corev1client.NewForConfig(restConfig)
+	if err != nil {
+		return nil
+	}
+
+	pod, err := client.Pods(namespace).Get(ctx, strings.TrimPrefix(podName, "pod/"), metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+
+	return pod
+}
+
+// lintBeforeRun prints the lint findings of the debug profile and refuses to run
+// profiles with error findings unless --allow-risky is set
+func lintBeforeRun(ctx context.Context, pod *corev1.Pod, streams genericiooptions.IOStreams) error {
+	findings, err := profile.LintProfile(ctx, &debugProfile, pod)
 	if err != nil {
-		return "", fmt.Errorf("get REST config: %w", err)
+		return fmt.Errorf("lint profile %q: %w", debugProfile.ProfileName, err)
+	}
+
+	risky := false
+	for _, f := range findings {
+		fmt.Fprintf(streams.ErrOut, "lint %s: %s: %s\n", f.Severity, f.Field, f.Message)
+		if f.Severity == profile.SeverityError {
+			risky = true
+		}
+	}
+
+	if risky && !flagAllowRisky {
+		return fmt.Errorf("profile %q violates lint rules - use --allow-risky to run it anyway", debugProfile.ProfileName)
 	}
 
-	podClient := corev1client.NewForConfigOrDie(restClient)
+	return nil
+}
+
+// checkPolicy refuses to run the debug profile if the policy denies the debug session,
+// denials are recorded in the audit log
+func checkPolicy(ctx context.Context, namespace string, pod *corev1.Pod, auditEntry *audit.Entry, streams genericiooptions.IOStreams) error {
+	kubeContext, err := currentKubeContext()
+	if err != nil {
+		return fmt.Errorf("get current kubeconfig context: %w", err)
+	}
 
-	matchingPods, err := podClient.Pods(namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: metav1.FormatLabelSelector(
-			&metav1.LabelSelector{
-				MatchLabels: debugProfile.MatchLabels,
-			}),
+	spec, err := debugProfile.DebugContainer(ctx)
+	if err != nil {
+		return fmt.Errorf("get spec of profile %q: %w", debugProfile.ProfileName, err)
+	}
+
+	input := &profile.PolicyInput{
+		Profile:   &debugProfile,
+		Spec:      spec,
+		Pod:       pod,
+		Namespace: namespace,
+		Context:   kubeContext,
+	}
+
+	if flagDebug {
+		if vars, err := input.Vars(); err == nil {
+			if data, err := json.Marshal(vars); err == nil {
+				fmt.Fprintf(streams.Out, "policy input: %s\n", data)
+			}
+		}
+	}
+
+	decision, err := profile.EvaluatePolicy(input)
+	if err != nil {
+		return fmt.Errorf("evaluate policy: %w", err)
+	}
+
+	if !decision.Allowed {
+		auditEntry.Denied = true
+		auditEntry.Reason = decision.Reason
+		recordAudit(auditEntry, streams)
+
+		return fmt.Errorf("profile %q %w: %s", debugProfile.ProfileName, profile.ErrPolicyDenied, decision.Reason)
+	}
+
+	return nil
+}
+
+// getTargetPods returns the names of all pods of the target workload or, without target,
+// of the pods selected by the workload and the label selector of the debug profile.
+// The field selector of the debug profile applies to both.
+func getTargetPods(ctx context.Context, client kubernetes.Interface, namespace string, target *profile.WorkloadRef) ([]string, error) {
+	selector, err := targetSelector(ctx, client, namespace, target)
+	if err != nil {
+		return nil, err
+	}
+
+	matchingPods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+		FieldSelector: debugProfile.FieldSelector,
 	})
 	if err != nil {
-		return "", fmt.Errorf("list pods in namespace %q: %w", namespace, err)
+		return nil, fmt.Errorf("list pods in namespace %q: %w", namespace, err)
 	}
 
 	if len(matchingPods.Items) == 0 {
-		return "", fmt.Errorf("no pods in namespace %s found with label selector %v", namespace, debugProfile.MatchLabels)
+		return nil, &noPodsError{namespace: namespace, labels: selector.String(), fields: debugProfile.FieldSelector}
 	}
 
-	var podName string
-
+	podNames := make([]string, 0, len(matchingPods.Items))
 	for _, pod := range matchingPods.Items {
-		podName = pod.Name
+		podNames = append(podNames, pod.Name)
 	}
 
-	return podName, nil
+	return podNames, nil
 }
 
 func getTargetNamespace() string {
@@ -0,0 +1,333 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/audit"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+	"github.com/bavarianbidi/kubectl-dpm/pkg/recording"
+)
+
+func Sessions(streams genericiooptions.IOStreams) *cobra.Command {
+	sessionsCmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "list, attach to and inspect the debug sessions created by dpm",
+		Long: "list, attach to and inspect the ephemeral containers created by 'kubectl dpm run'. " +
+			"They are tagged by the name prefix " + profile.SessionContainerPrefix + " and, with sessions.annotate, " +
+			"by a pod annotation with the profile, spec digest, user and start time.",
+	}
+
+	sessionsCmd.AddCommand(
+		sessionsList(),
+		sessionsAttach(streams),
+		sessionsLogs(),
+		sessionsReplay(),
+	)
+
+	return sessionsCmd
+}
+
+func sessionsList() *cobra.Command {
+	var (
+		flagAllNamespaces bool
+		flagAllContainers bool
+	)
+
+	listCmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "list the debug sessions of a namespace",
+		Example: `  kubectl dpm sessions ls --namespace default
+  kubectl dpm sessions ls -A --all-containers`,
+		Args: cobra.NoArgs,
+
+		RunE: func(c *cobra.Command, _ []string) error {
+			namespace := metav1.NamespaceAll
+			if !flagAllNamespaces {
+				var err error
+				if namespace, _, err = MatchVersionKubeConfigFlags.ToRawKubeConfigLoader().Namespace(); err != nil {
+					return fmt.Errorf("get namespace: %w", err)
+				}
+			}
+
+			clientset, err := newClientset()
+			if err != nil {
+				return err
+			}
+
+			sessions, err := listSessions(c.Context(), clientset, namespace, flagAllContainers)
+			if err != nil {
+				return err
+			}
+
+			return printSessions(c.OutOrStdout(), sessions, flagAllNamespaces, time.Now())
+		},
+	}
+
+	listCmd.Flags().BoolVarP(&flagAllNamespaces, "all-namespaces", "A", false, "list the debug sessions of all namespaces")
+	listCmd.Flags().BoolVar(&flagAllContainers, "all-containers", false, "also list ephemeral containers not created by dpm")
+
+	return listCmd
+}
+
+func sessionsAttach(streams genericiooptions.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "attach POD [CONTAINER]",
+		Short: "attach to a running debug session",
+		Long:  "attach to a running debug session, without a container to the latest running debug session of the pod",
+		Example: `  kubectl dpm sessions attach webapp-7d9c6b5f4-x2x9q
+  kubectl dpm sessions attach webapp-7d9c6b5f4-x2x9q dpm-webapp-x7k2p`,
+		Args: cobra.RangeArgs(1, 2),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			session, err := findSession(c.Context(), args, true)
+			if err != nil {
+				return err
+			}
+
+			if !session.Running {
+				return fmt.Errorf("debug session %s of pod %s is not running (%s)", session.Container, session.Pod, session.State)
+			}
+
+			if !session.TTY {
+				fmt.Fprintf(streams.ErrOut, "debug session %s has no TTY, attaching without one\n", session.Container)
+			}
+
+			return attachContainer(c.Context(), session.Namespace, session.Pod, session.Container, session.TTY, streams, nil)
+		},
+	}
+}
+
+func sessionsLogs() *cobra.Command {
+	var logOptions corev1.PodLogOptions
+
+	logsCmd := &cobra.Command{
+		Use:   "logs POD [CONTAINER]",
+		Short: "print the logs of a debug session",
+		Long:  "print the logs of a debug session, without a container of the latest debug session of the pod",
+		Example: `  kubectl dpm sessions logs webapp-7d9c6b5f4-x2x9q
+  kubectl dpm sessions logs webapp-7d9c6b5f4-x2x9q dpm-webapp-x7k2p --follow`,
+		Args: cobra.RangeArgs(1, 2),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			session, err := findSession(c.Context(), args, false)
+			if err != nil {
+				return err
+			}
+
+			clientset, err := newClientset()
+			if err != nil {
+				return err
+			}
+
+			logOptions.Container = session.Container
+			logs, err := clientset.CoreV1().Pods(session.Namespace).GetLogs(session.Pod, &logOptions).Stream(c.Context())
+			if err != nil {
+				return fmt.Errorf("get logs of debug session %s: %w", session.Container, err)
+			}
+			defer logs.Close()
+
+			_, err = io.Copy(c.OutOrStdout(), logs)
+			return err
+		},
+	}
+
+	logsCmd.Flags().BoolVarP(&logOptions.Follow, "follow", "f", false, "stream the logs")
+	logsCmd.Flags().BoolVar(&logOptions.Timestamps, "timestamps", false, "prefix every line with its timestamp")
+
+	return logsCmd
+}
+
+func sessionsReplay() *cobra.Command {
+	var options recording.ReplayOptions
+
+	replayCmd := &cobra.Command{
+		Use:   "replay FILE",
+		Short: "replay a debug session recorded with run --record",
+		Example: `  kubectl dpm sessions replay ~/.kube-dpm/recordings/20250601T120000Z-webapp-webapp-7d9c6b5f4-x2x9q.cast
+  kubectl dpm sessions replay session.cast --speed 2 --idle-limit 1s`,
+		Args: cobra.ExactArgs(1),
+
+		RunE: func(c *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("open recording: %w", err)
+			}
+			defer f.Close()
+
+			header, events, err := recording.Read(f)
+			if err != nil {
+				return fmt.Errorf("read recording %s: %w", args[0], err)
+			}
+
+			if header.Title != "" {
+				fmt.Fprintf(c.ErrOrStderr(), "replaying %s (%dx%d)\n", header.Title, header.Width, header.Height)
+			}
+
+			return recording.Replay(c.Context(), c.OutOrStdout(), events, options)
+		},
+	}
+
+	replayCmd.Flags().Float64Var(&options.Speed, "speed", 1, "playback speed, e.g. 2 plays twice as fast")
+	replayCmd.Flags().DurationVar(&options.IdleLimit, "idle-limit", 0, "limit the pauses between outputs, e.g. 2s")
+
+	return replayCmd
+}
+
+// findSession returns the ephemeral container args[1] of the pod args[0], or the latest
+// debug session of the pod (only running ones if running is set)
+func findSession(ctx context.Context, args []string, running bool) (*profile.Session, error) {
+	namespace, _, err := MatchVersionKubeConfigFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("get namespace: %w", err)
+	}
+
+	clientset, err := newClientset()
+	if err != nil {
+		return nil, err
+	}
+
+	podName := strings.TrimPrefix(args[0], "pod/")
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get pod %q: %w", podName, err)
+	}
+
+	container := ""
+	if len(args) == 2 {
+		container = args[1]
+	}
+
+	return selectSession(pod, container, running)
+}
+
+// selectSession returns the ephemeral container of the pod, or without a name the latest
+// debug session (only running ones if running is set)
+func selectSession(pod *corev1.Pod, container string, running bool) (*profile.Session, error) {
+	if container != "" {
+		for _, s := range profile.PodEphemeralContainers(pod) {
+			if s.Container == container {
+				return &s, nil
+			}
+		}
+		return nil, fmt.Errorf("pod %s has no ephemeral container %q", pod.Name, container)
+	}
+
+	sessions := profile.PodSessions(pod)
+	for i := len(sessions) - 1; i >= 0; i-- {
+		if !running || sessions[i].Running {
+			return &sessions[i], nil
+		}
+	}
+
+	if running {
+		return nil, fmt.Errorf("pod %s has no running debug session", pod.Name)
+	}
+	return nil, fmt.Errorf("pod %s has no debug session", pod.Name)
+}
+
+// listSessions returns the debug sessions of all pods of the namespace (all namespaces if empty),
+// with all ephemeral containers if all is set
+func listSessions(ctx context.Context, client kubernetes.Interface, namespace string, all bool) ([]profile.Session, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list pods in namespace %q: %w", namespace, err)
+	}
+
+	var sessions []profile.Session
+	for i := range pods.Items {
+		if all {
+			sessions = append(sessions, profile.PodEphemeralContainers(&pods.Items[i])...)
+		} else {
+			sessions = append(sessions, profile.PodSessions(&pods.Items[i])...)
+		}
+	}
+
+	return sessions, nil
+}
+
+func printSessions(out io.Writer, sessions []profile.Session, withNamespace bool, now time.Time) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	header := "POD\tCONTAINER\tPROFILE\tIMAGE\tSTATUS\tAGE"
+	if withNamespace {
+		header = "NAMESPACE\t" + header
+	}
+	fmt.Fprintln(w, header)
+
+	for _, s := range sessions {
+		age := "<unknown>"
+		if !s.StartTime.IsZero() {
+			age = duration.HumanDuration(now.Sub(s.StartTime))
+		}
+
+		profileName := s.Profile
+		if profileName == "" {
+			profileName = "-"
+		}
+
+		if withNamespace {
+			fmt.Fprintf(w, "%s\t", s.Namespace)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Pod, s.Container, profileName, s.Image, s.State, age)
+	}
+
+	return w.Flush()
+}
+
+// annotateSession annotates the target pod with the metadata of the debug session,
+// a failure only gets reported
+func annotateSession(ctx context.Context, namespace, podName, container string, auditEntry *audit.Entry, streams genericiooptions.IOStreams) {
+	limits := debugProfile.SessionLimits()
+
+	key, value, err := profile.SessionAnnotation(container, &profile.SessionMetadata{
+		Profile:     auditEntry.Profile,
+		SpecDigest:  auditEntry.SpecDigest,
+		User:        auditEntry.User,
+		StartTime:   time.Now().UTC().Truncate(time.Second),
+		MaxDuration: profile.FormatLimit(limits.MaxDuration),
+		IdleTimeout: profile.FormatLimit(limits.IdleTimeout),
+	})
+	if err == nil {
+		err = patchPodAnnotation(ctx, namespace, strings.TrimPrefix(podName, "pod/"), key, value)
+	}
+	if err != nil {
+		fmt.Fprintf(streams.ErrOut, "warning: annotate pod with debug session: %v\n", err)
+	}
+}
+
+func patchPodAnnotation(ctx context.Context, namespace, podName, key, value string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{key: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	clientset, err := newClientset()
+	if err != nil {
+		return err
+	}
+
+	_, err = clientset.CoreV1().Pods(namespace).Patch(ctx, podName, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
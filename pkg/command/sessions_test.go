@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func sessionTestPod(namespace, name string, containers map[string]*corev1.ContainerState) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+
+	// keep the order of creation stable
+	for _, c := range []string{"debugger-abcde", "dpm-webapp-x7k2p", "dpm-webapp-q8z4m"} {
+		state, ok := containers[c]
+		if !ok {
+			continue
+		}
+		pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: c, Image: "busybox", TTY: true},
+		})
+		pod.Status.EphemeralContainerStatuses = append(pod.Status.EphemeralContainerStatuses, corev1.ContainerStatus{Name: c, State: *state})
+	}
+
+	return pod
+}
+
+func TestListSessions(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	running := &corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-5 * time.Minute))}}
+
+	client := fake.NewSimpleClientset(
+		sessionTestPod("default", "webapp-0", map[string]*corev1.ContainerState{"debugger-abcde": running, "dpm-webapp-x7k2p": running}),
+		sessionTestPod("payments", "db-0", map[string]*corev1.ContainerState{"dpm-webapp-q8z4m": running}),
+	)
+
+	tests := []struct {
+		name          string
+		namespace     string
+		all           bool
+		withNamespace bool
+		want          string
+	}{
+		{
+			name:      "namespace",
+			namespace: "default",
+			want: `POD       CONTAINER         PROFILE  IMAGE    STATUS   AGE
+webapp-0  dpm-webapp-x7k2p  webapp   busybox  Running  5m
+`,
+		},
+		{
+			name:          "all namespaces and containers",
+			all:           true,
+			withNamespace: true,
+			want: `NAMESPACE  POD       CONTAINER         PROFILE  IMAGE    STATUS   AGE
+default    webapp-0  debugger-abcde    -        busybox  Running  5m
+default    webapp-0  dpm-webapp-x7k2p  webapp   busybox  Running  5m
+payments   db-0      dpm-webapp-q8z4m  webapp   busybox  Running  5m
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions, err := listSessions(context.Background(), client, tt.namespace, tt.all)
+			if err != nil {
+				t.Fatalf("listSessions() error = %v", err)
+			}
+
+			var out bytes.Buffer
+			if err := printSessions(&out, sessions, tt.withNamespace, now); err != nil {
+				t.Fatal(err)
+			}
+
+			if out.String() != tt.want {
+				t.Errorf("printSessions() =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectSession(t *testing.T) {
+	running := &corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	terminated := &corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}}
+
+	pod := sessionTestPod("default", "webapp-0", map[string]*corev1.ContainerState{
+		"debugger-abcde":   running,
+		"dpm-webapp-x7k2p": running,
+		"dpm-webapp-q8z4m": terminated,
+	})
+
+	tests := []struct {
+		name      string
+		container string
+		running   bool
+		want      string
+		wantErr   string
+	}{
+		{name: "latest session", want: "dpm-webapp-q8z4m"},
+		{name: "latest running session", running: true, want: "dpm-webapp-x7k2p"},
+		{name: "named ephemeral container", container: "debugger-abcde", running: true, want: "debugger-abcde"},
+		{name: "unknown container", container: "app", wantErr: `pod webapp-0 has no ephemeral container "app"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectSession(pod, tt.container, tt.running)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("selectSession() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectSession() error = %v", err)
+			}
+			if got.Container != tt.want {
+				t.Errorf("selectSession() = %s, want %s", got.Container, tt.want)
+			}
+		})
+	}
+
+	if _, err := selectSession(sessionTestPod("default", "webapp-1", nil), "", true); err == nil {
+		t.Errorf("selectSession() of a pod without debug sessions succeeded")
+	}
+}
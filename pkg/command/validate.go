@@ -3,29 +3,177 @@
 package command
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/bavarianbidi/kubectl-dpm/pkg/config"
 	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
 )
 
 func ValidateDebugProfileFile() *cobra.Command {
-	return &cobra.Command{
+	var (
+		flagLive   bool
+		flagStrict bool
+		flagOutput string
+	)
+
+	validateCmd := &cobra.Command{
 		Use:   "validate",
 		Short: "validate debug profiles configuration file",
+		Long: "validate debug profiles configuration file. All findings are reported and the command fails if there is " +
+			"an error (or with --strict a warning). With --live every profile of the current kubeconfig context " +
+			"is additionally checked against its target pod in the cluster.",
+		Example: `  kubectl dpm validate
+  kubectl dpm validate --strict -o junit > dpm-report.xml
+  kubectl dpm validate --live`,
 
 		RunE: func(c *cobra.Command, _ []string) error {
+			writeReport, ok := reportWriters[flagOutput]
+			if !ok {
+				return fmt.Errorf("unknown output format %q (valid formats: text, json, junit)", flagOutput)
+			}
+
 			if err := config.GenerateConfig(); err != nil {
 				return fmt.Errorf("generate config: %w", err)
 			}
 
-			if err := profile.ValidateAllProfiles(c.Context()); err != nil {
-				return fmt.Errorf("validate profiles: %w", err)
+			report := profile.CollectFindings(c.Context())
+
+			var targetPods map[string]*corev1.Pod
+			if flagLive {
+				var err error
+				if targetPods, err = validateInCluster(c.Context(), report); err != nil {
+					return err
+				}
+			}
+
+			lintProfiles(c.Context(), report, profile.Config.Profiles, targetPods)
+
+			if err := writeReport(c.OutOrStdout(), report, flagStrict); err != nil {
+				return fmt.Errorf("write report: %w", err)
+			}
+
+			// the findings are already part of the report, don't print them again
+			c.SilenceUsage = true
+			if report.Failed(flagStrict) {
+				return fmt.Errorf("validation failed with %d error(s) and %d warning(s)",
+					report.Count(profile.SeverityError), report.Count(profile.SeverityWarning))
 			}
 
 			return nil
 		},
 	}
+
+	flags := validateCmd.Flags()
+	flags.BoolVar(&flagLive, "live", false, "check the profiles against their target pods in the cluster")
+	flags.BoolVar(&flagAllContexts, allContextsFlagName, false, "check all profiles against the cluster, not only the ones of the current kubeconfig context")
+	flags.BoolVar(&flagStrict, "strict", false, "fail on warnings")
+	flags.StringVarP(&flagOutput, "output", "o", "text", "output format (text, json, junit)")
+
+	return validateCmd
+}
+
+// validateInCluster checks all valid profiles of the current kubeconfig context
+// against their target pods, adds the results to the report and returns the
+// target pods by profile name
+func validateInCluster(ctx context.Context, report *profile.Report) (map[string]*corev1.Pod, error) {
+	profiles, err := scopedProfiles(profile.Config.Profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := newClientset()
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfigNamespace, _, err := MatchVersionKubeConfigFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("get namespace: %w", err)
+	}
+
+	targetPods := map[string]*corev1.Pod{}
+
+	for _, p := range profiles {
+		// a profile with configuration errors can't be checked against the cluster
+		if p.ProfileName == "" || hasErrors(report.ProfileFindings(p.ProfileName)) {
+			continue
+		}
+
+		namespaces := []string{p.Namespace}
+		switch {
+		case p.Namespaces.IsSet():
+			if namespaces, err = p.TargetNamespaces(ctx, clientset.CoreV1()); err != nil {
+				clusterReport := failedClusterReport(p.ProfileName, "", err)
+				report.AddClusterReport(&clusterReport)
+				continue
+			}
+		case p.Namespace == "":
+			namespaces = []string{kubeconfigNamespace}
+		}
+
+		// a profile with several namespaces is checked in the first namespace with a target pod
+		var clusterReport profile.ClusterReport
+		for _, namespace := range namespaces {
+			if err := resolveProfileWorkload(ctx, clientset, namespace, &p); err != nil {
+				clusterReport = failedClusterReport(p.ProfileName, namespace, err)
+				continue
+			}
+
+			clusterReport = profile.ValidateProfileInCluster(ctx, clientset.CoreV1(), p, namespace)
+			if clusterReport.TargetPod != nil {
+				break
+			}
+		}
+
+		report.AddClusterReport(&clusterReport)
+		targetPods[p.ProfileName] = clusterReport.TargetPod
+	}
+
+	return targetPods, nil
+}
+
+// failedClusterReport is the report of a profile whose target pod can't be resolved
+func failedClusterReport(profileName, namespace string, err error) profile.ClusterReport {
+	return profile.ClusterReport{
+		Profile:   profileName,
+		Namespace: namespace,
+		Checks:    []profile.ClusterCheck{{Name: "pod", Status: profile.CheckFail, Message: err.Error()}},
+	}
+}
+
+// lintProfiles adds the lint findings of all valid profiles to the report.
+// Rules which need the target pod only run for profiles with a pod in targetPods.
+func lintProfiles(ctx context.Context, report *profile.Report, profiles []profile.Profile, targetPods map[string]*corev1.Pod) {
+	for i := range profiles {
+		p := &profiles[i]
+		if p.ProfileName == "" || hasErrors(report.ProfileFindings(p.ProfileName)) {
+			continue
+		}
+
+		// configmap sources are only instantiated at runtime
+		if p.ProfileSource.Type == profile.SourceTypeConfigMap && p.GetSource() == nil {
+			continue
+		}
+
+		findings, err := profile.LintProfile(ctx, p, targetPods[p.ProfileName])
+		if err != nil {
+			report.Add(profile.SeverityError, p.ProfileName, profile.FieldSpec, "lint: %v", err)
+			continue
+		}
+
+		report.Findings = append(report.Findings, findings...)
+	}
+}
+
+func hasErrors(findings []profile.Finding) bool {
+	for _, f := range findings {
+		if f.Severity == profile.SeverityError {
+			return true
+		}
+	}
+	return false
 }
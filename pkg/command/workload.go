@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+// workload is a resolved workload with the selector of its pods
+type workload struct {
+	Ref       profile.WorkloadRef
+	Namespace string
+	Selector  *metav1.LabelSelector
+	// PodMeta and PodSpec are taken from the pod or the pod template of the workload
+	PodMeta metav1.ObjectMeta
+	PodSpec corev1.PodSpec
+}
+
+// getWorkload fetches the referenced workload. For pods the selector of the
+// owning workload is used, or the labels of the pod if it isn't owned by one.
+func getWorkload(ctx context.Context, client kubernetes.Interface, namespace string, ref profile.WorkloadRef) (*workload, error) {
+	w := &workload{Ref: ref, Namespace: namespace}
+
+	switch ref.Kind {
+	case profile.WorkloadKindPod:
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get pod %q in namespace %q: %w", ref.Name, namespace, err)
+		}
+		w.PodMeta, w.PodSpec = pod.ObjectMeta, pod.Spec
+
+		owner, err := ownerWorkload(ctx, client, namespace, pod.OwnerReferences)
+		if err != nil {
+			return nil, err
+		}
+		if owner != nil {
+			w.Selector = owner.Selector
+		} else {
+			w.Selector = &metav1.LabelSelector{MatchLabels: podLabels(pod.Labels)}
+		}
+	case profile.WorkloadKindDeployment:
+		d, err := client.AppsV1().Deployments(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get deployment %q in namespace %q: %w", ref.Name, namespace, err)
+		}
+		w.Selector = d.Spec.Selector
+		w.PodMeta, w.PodSpec = d.Spec.Template.ObjectMeta, d.Spec.Template.Spec
+	case profile.WorkloadKindStatefulSet:
+		s, err := client.AppsV1().StatefulSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get statefulset %q in namespace %q: %w", ref.Name, namespace, err)
+		}
+		w.Selector = s.Spec.Selector
+		w.PodMeta, w.PodSpec = s.Spec.Template.ObjectMeta, s.Spec.Template.Spec
+	case profile.WorkloadKindDaemonSet:
+		d, err := client.AppsV1().DaemonSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get daemonset %q in namespace %q: %w", ref.Name, namespace, err)
+		}
+		w.Selector = d.Spec.Selector
+		w.PodMeta, w.PodSpec = d.Spec.Template.ObjectMeta, d.Spec.Template.Spec
+	case profile.WorkloadKindReplicaSet:
+		rs, err := client.AppsV1().ReplicaSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get replicaset %q in namespace %q: %w", ref.Name, namespace, err)
+		}
+		w.Selector = rs.Spec.Selector
+		w.PodMeta, w.PodSpec = rs.Spec.Template.ObjectMeta, rs.Spec.Template.Spec
+
+		// prefer the selector of the owning deployment, it survives rollouts
+		owner, err := ownerWorkload(ctx, client, namespace, rs.OwnerReferences)
+		if err != nil {
+			return nil, err
+		}
+		if owner != nil {
+			w.Selector = owner.Selector
+		}
+	case profile.WorkloadKindJob:
+		j, err := client.BatchV1().Jobs(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get job %q in namespace %q: %w", ref.Name, namespace, err)
+		}
+		w.Selector = j.Spec.Selector
+		w.PodMeta, w.PodSpec = j.Spec.Template.ObjectMeta, j.Spec.Template.Spec
+	default:
+		return nil, fmt.Errorf("unsupported resource type %q", ref.Kind)
+	}
+
+	return w, nil
+}
+
+// ownerWorkload resolves the controlling owner of an object, ReplicaSets are
+// followed up to their Deployment. It returns nil if there is no supported owner.
+func ownerWorkload(ctx context.Context, client kubernetes.Interface, namespace string, owners []metav1.OwnerReference) (*workload, error) {
+	owner := metav1.GetControllerOfNoCopy(&metav1.ObjectMeta{OwnerReferences: owners})
+	if owner == nil {
+		return nil, nil
+	}
+
+	kind, ok := profile.WorkloadKind(owner.Kind)
+	if !ok || kind == profile.WorkloadKindPod {
+		return nil, nil
+	}
+
+	return getWorkload(ctx, client, namespace, profile.WorkloadRef{Kind: kind, Name: owner.Name})
+}
+
+// podLabels returns the labels of a pod without the ones generated by controllers
+func podLabels(labels map[string]string) map[string]string {
+	result := maps.Clone(labels)
+
+	for _, generated := range []string{
+		appsv1.DefaultDeploymentUniqueLabelKey,
+		appsv1.ControllerRevisionHashLabelKey,
+		appsv1.StatefulSetPodNameLabel,
+		"pod-template-generation",
+	} {
+		delete(result, generated)
+	}
+
+	return result
+}
+
+// container returns the container with the given name, or the default container
+// of the pod (annotation "kubectl.kubernetes.io/default-container" or the first one)
+func (w *workload) container(name string) (*corev1.Container, error) {
+	if name == "" {
+		name = w.PodMeta.Annotations[defaultContainerAnnotation]
+	}
+
+	if name == "" {
+		if len(w.PodSpec.Containers) == 0 {
+			return nil, fmt.Errorf("%s has no containers", w.Ref)
+		}
+		return &w.PodSpec.Containers[0], nil
+	}
+
+	for i := range w.PodSpec.Containers {
+		if w.PodSpec.Containers[i].Name == name {
+			return &w.PodSpec.Containers[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("container %q not found in %s", name, w.Ref)
+}
+
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// parseTarget parses the optional target argument of run, it's either the name of a pod
+// or a workload whose pods are the targets
+func parseTarget(args []string) (string, *profile.WorkloadRef, error) {
+	if len(args) == 0 {
+		return "", nil, nil
+	}
+
+	ref, err := profile.ParseWorkloadRef(args[0])
+	if err != nil {
+		return "", nil, err
+	}
+	if ref.Kind == profile.WorkloadKindPod {
+		return ref.Name, nil, nil
+	}
+
+	return "", &ref, nil
+}
+
+// targetSelector returns the label selector of the pods of the target workload or, without
+// target, the selector of the workload, matchLabels and matchExpressions of the debug profile
+func targetSelector(ctx context.Context, client kubernetes.Interface, namespace string, target *profile.WorkloadRef) (labels.Selector, error) {
+	if target != nil {
+		w, err := getWorkload(ctx, client, namespace, *target)
+		if err != nil {
+			return nil, err
+		}
+		return workloadSelector(w)
+	}
+
+	if !debugProfile.HasPodSelector() {
+		return nil, fmt.Errorf("no target pod specified and profile %q has no workload or selector", debugProfile.ProfileName)
+	}
+
+	if err := resolveProfileWorkload(ctx, client, namespace, &debugProfile); err != nil {
+		return nil, err
+	}
+
+	return debugProfile.PodSelector()
+}
+
+// resolveProfileWorkload sets the selector of the workload of the profile, if it has one
+func resolveProfileWorkload(ctx context.Context, client kubernetes.Interface, namespace string, p *profile.Profile) error {
+	if p.Workload == "" {
+		return nil
+	}
+
+	ref, err := profile.ParseWorkloadRef(p.Workload)
+	if err != nil {
+		return fmt.Errorf("workload of profile %q: %w", p.ProfileName, err)
+	}
+
+	w, err := getWorkload(ctx, client, namespace, ref)
+	if err != nil {
+		return fmt.Errorf("workload of profile %q: %w", p.ProfileName, err)
+	}
+	if w.Selector == nil {
+		return fmt.Errorf("workload of profile %q: %s has no selector", p.ProfileName, ref)
+	}
+
+	p.SetWorkloadSelector(w.Selector)
+
+	return nil
+}
+
+// workloadSelector returns the selector of the pods of the workload
+func workloadSelector(w *workload) (labels.Selector, error) {
+	if w.Selector == nil {
+		return nil, fmt.Errorf("%s has no selector", w.Ref)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(w.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("selector of %s: %w", w.Ref, err)
+	}
+
+	return selector, nil
+}
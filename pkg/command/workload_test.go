@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: MIT
+
+package command
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func TestGetWorkload(t *testing.T) {
+	controller := true
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"run": "webapp"}}
+	containers := []corev1.Container{{Name: "sidecar"}, {Name: "webapp"}}
+
+	objects := []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "webapp", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: selector,
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}},
+			},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "webapp-7d9c6b5f4", Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "webapp", Controller: &controller}},
+			},
+			Spec: appsv1.ReplicaSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"run": "webapp", "pod-template-hash": "7d9c6b5f4"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "webapp-7d9c6b5f4-x2x9q", Namespace: "default",
+				Labels:          map[string]string{"run": "webapp", "pod-template-hash": "7d9c6b5f4"},
+				Annotations:     map[string]string{defaultContainerAnnotation: "webapp"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "webapp-7d9c6b5f4", Controller: &controller}},
+			},
+			Spec: corev1.PodSpec{Containers: containers},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "standalone", Namespace: "default",
+				Labels: map[string]string{"run": "standalone", "pod-template-hash": "abc"},
+			},
+			Spec: corev1.PodSpec{Containers: containers},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		ref           profile.WorkloadRef
+		wantSelector  map[string]string
+		wantContainer string
+		wantErr       bool
+	}{
+		{
+			name:          "deployment",
+			ref:           profile.WorkloadRef{Kind: profile.WorkloadKindDeployment, Name: "webapp"},
+			wantSelector:  map[string]string{"run": "webapp"},
+			wantContainer: "sidecar",
+		},
+		{
+			name:          "pod owned by a deployment",
+			ref:           profile.WorkloadRef{Kind: profile.WorkloadKindPod, Name: "webapp-7d9c6b5f4-x2x9q"},
+			wantSelector:  map[string]string{"run": "webapp"},
+			wantContainer: "webapp",
+		},
+		{
+			name:          "pod without owner",
+			ref:           profile.WorkloadRef{Kind: profile.WorkloadKindPod, Name: "standalone"},
+			wantSelector:  map[string]string{"run": "standalone"},
+			wantContainer: "sidecar",
+		},
+		{
+			name:    "missing workload",
+			ref:     profile.WorkloadRef{Kind: profile.WorkloadKindStatefulSet, Name: "db"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(objects...)
+
+			got, err := getWorkload(context.Background(), client, "default", tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getWorkload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(got.Selector.MatchLabels, tt.wantSelector) {
+				t.Errorf("getWorkload() selector = %v, want %v", got.Selector.MatchLabels, tt.wantSelector)
+			}
+
+			container, err := got.container("")
+			if err != nil {
+				t.Fatalf("container() error = %v", err)
+			}
+			if container.Name != tt.wantContainer {
+				t.Errorf("container() = %q, want %q", container.Name, tt.wantContainer)
+			}
+		})
+	}
+}
+
+func TestTargetSelector(t *testing.T) {
+	oldProfile := debugProfile
+	t.Cleanup(func() { debugProfile = oldProfile })
+
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "webapp", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"run": "webapp"}},
+			},
+		},
+	)
+
+	tests := []struct {
+		name    string
+		profile profile.Profile
+		target  *profile.WorkloadRef
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "target workload",
+			profile: profile.Profile{MatchLabels: map[string]string{"app": "other"}},
+			target:  &profile.WorkloadRef{Kind: profile.WorkloadKindDeployment, Name: "webapp"},
+			want:    "run=webapp",
+		},
+		{
+			name:    "profile workload and matchLabels",
+			profile: profile.Profile{Workload: "deploy/webapp", MatchLabels: map[string]string{"track": "canary"}},
+			want:    "run=webapp,track=canary",
+		},
+		{
+			name:    "profile matchLabels",
+			profile: profile.Profile{MatchLabels: map[string]string{"app": "webapp"}},
+			want:    "app=webapp",
+		},
+		{
+			name:    "missing profile workload",
+			profile: profile.Profile{Workload: "deployment/db"},
+			wantErr: true,
+		},
+		{
+			name:    "no selector",
+			profile: profile.Profile{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			debugProfile = tt.profile
+
+			got, err := targetSelector(context.Background(), client, "default", tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("targetSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("targetSelector() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -4,10 +4,11 @@ package config
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/parsers/yaml"
-	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/providers/rawbytes"
 
 	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
 )
@@ -17,17 +18,47 @@ import (
 var ConfigurationFile string
 
 func GenerateConfig() error {
+	data, err := os.ReadFile(ConfigurationFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file: %w", err)
+	}
+
+	return LoadConfig(ConfigurationFile, data)
+}
+
+// LoadConfig loads the configuration data of file into the global Config struct
+func LoadConfig(file string, data []byte) error {
+	cfg, err := parseConfig(file, data)
+	if err != nil {
+		return err
+	}
+
+	profile.Config = cfg
+
+	return nil
+}
+
+// parseConfig parses the configuration data of file without touching the global Config struct
+func parseConfig(file string, data []byte) (profile.CustomDebugProfile, error) {
+	var cfg profile.CustomDebugProfile
+
 	k := koanf.New(".")
 
+	// reject unknown fields and values which don't match the schema
+	// before koanf silently drops them
+	if err := ValidateSchema(file, data); err != nil {
+		return cfg, fmt.Errorf("invalid config file: %w", err)
+	}
+
 	// load config from file if given or from default paths
-	if err := k.Load(file.Provider(ConfigurationFile), yaml.Parser()); err != nil {
-		return fmt.Errorf("failed to load config file: %w", err)
+	if err := k.Load(rawbytes.Provider(data), yaml.Parser()); err != nil {
+		return cfg, fmt.Errorf("failed to load config file: %w", err)
 	}
 
-	// unmarshal all koanf config keys into the global Config struct
-	if err := k.Unmarshal("", &profile.Config); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+	// unmarshal all koanf config keys into the Config struct
+	if err := k.Unmarshal("", &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	return nil
+	return cfg, nil
 }
@@ -5,6 +5,7 @@ package config
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
 )
@@ -50,10 +51,45 @@ func TestGenerateConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:       "session limits",
+			configFile: "test_data/limits_config.yaml",
+			expectedConfig: profile.CustomDebugProfile{
+				Sessions: profile.SessionsConfig{
+					Limits: profile.SessionLimits{MaxDuration: 8 * time.Hour, IdleTimeout: 30 * time.Minute},
+				},
+				Profiles: []profile.Profile{
+					{
+						ProfileName: "prod-db",
+						Profile:     "netadmin",
+						Limits:      profile.SessionLimits{MaxDuration: 90 * time.Minute, Kill: true},
+					},
+				},
+			},
+		},
+		{
+			name:       "pod selectors",
+			configFile: "test_data/selector_config.yaml",
+			expectedConfig: profile.CustomDebugProfile{
+				Profiles: []profile.Profile{
+					{
+						ProfileName: "canary",
+						Profile:     "netadmin",
+						Workload:    "deployment/webapp",
+						MatchLabels: map[string]string{"app": "webapp"},
+						MatchExpressions: []profile.LabelRequirement{
+							{Key: "track", Operator: "In", Values: []string{"canary"}},
+							{Key: "legacy", Operator: "DoesNotExist"},
+						},
+						FieldSelector: "status.phase=Running",
+					},
+				},
+			},
+		},
 		{
 			name:       "invalid config file",
 			configFile: "test_data/invalid_config.yaml",
-			wantErr:    false,
+			wantErr:    true,
 		},
 	}
 
@@ -0,0 +1,581 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+// Document is a round-trippable YAML model of a configuration file.
+// In contrast to the koanf based loading, the parsed YAML nodes are only used to
+// locate profiles. Changes are spliced into the original lines, so comments, blank
+// lines and the formatting of all untouched parts are kept byte by byte.
+type Document struct {
+	file  string
+	lines []string
+	root  yaml.Node
+}
+
+// LoadDocument parses the configuration file into a Document
+func LoadDocument(file string) (*Document, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	return ParseDocument(file, data)
+}
+
+// ParseDocument parses configuration data into a Document
+func ParseDocument(file string, data []byte) (*Document, error) {
+	d := &Document{file: file, lines: strings.SplitAfter(string(data), "\n")}
+
+	if err := d.parse(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// parse re-parses the lines of the document after a change
+func (d *Document) parse() error {
+	d.root = yaml.Node{}
+
+	if err := yaml.Unmarshal([]byte(strings.Join(d.lines, "")), &d.root); err != nil {
+		return fmt.Errorf("parse %s: %w", d.file, err)
+	}
+
+	if d.root.Kind == 0 {
+		return nil
+	}
+
+	if d.root.Kind != yaml.DocumentNode || len(d.root.Content) == 0 || d.root.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("parse %s: expected a mapping at the top level", d.file)
+	}
+
+	return nil
+}
+
+// profiles returns the sequence node of all profiles or nil
+func (d *Document) profiles() *yaml.Node {
+	if d.root.Kind == 0 {
+		return nil
+	}
+
+	profiles := mappingValue(d.root.Content[0], "profiles")
+	if profiles == nil || profiles.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	return profiles
+}
+
+// profileNode returns the node of the profile with the given name or nil
+func (d *Document) profileNode(name string) *yaml.Node {
+	profiles := d.profiles()
+	if profiles == nil {
+		return nil
+	}
+
+	idx := slices.IndexFunc(profiles.Content, func(n *yaml.Node) bool {
+		return profileName(n) == name
+	})
+	if idx == -1 {
+		return nil
+	}
+
+	return profiles.Content[idx]
+}
+
+// Profile returns a copy of the YAML node of the profile with the given name
+func (d *Document) Profile(name string) (*yaml.Node, error) {
+	node := d.profileNode(name)
+	if node == nil {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+
+	cp := copyNode(node)
+	// comments above the profile are kept in place and not part of the profile
+	cp.HeadComment = ""
+	if len(cp.Content) > 0 {
+		cp.Content[0].HeadComment = ""
+	}
+
+	return cp, nil
+}
+
+// AddProfile appends a new profile to the document
+func (d *Document) AddProfile(node *yaml.Node) error {
+	name := profileName(node)
+	if name == "" {
+		return fmt.Errorf("profile is missing a name")
+	}
+
+	if d.profileNode(name) != nil {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+
+	indent := "  "
+	profiles := d.profiles()
+
+	if profiles != nil && len(profiles.Content) > 0 {
+		if profiles.Style&yaml.FlowStyle != 0 {
+			return fmt.Errorf("profiles in flow style are not supported, please edit the config file manually")
+		}
+
+		last := profiles.Content[len(profiles.Content)-1]
+		start, end, err := d.itemRange(last)
+		if err != nil {
+			return err
+		}
+		indent = itemIndent(d.lines[start])
+
+		item, err := renderItem(node, indent)
+		if err != nil {
+			return err
+		}
+
+		d.splice(end, end, item)
+
+		return d.parse()
+	}
+
+	item, err := renderItem(node, indent)
+	if err != nil {
+		return err
+	}
+
+	idx := -1
+	if d.root.Kind != 0 {
+		idx = mappingKeyIndex(d.root.Content[0], "profiles")
+	}
+
+	if idx == -1 {
+		// append a new profiles key at the end of the file
+		if n := len(d.lines); n > 0 && d.lines[n-1] != "" && !strings.HasSuffix(d.lines[n-1], "\n") {
+			d.lines[n-1] += "\n"
+		}
+		d.splice(len(d.lines), len(d.lines), append([]string{"profiles:\n"}, item...))
+
+		return d.parse()
+	}
+
+	// replace an empty or null profiles value, e.g. "profiles: []"
+	key := d.root.Content[0].Content[idx]
+	line := d.lines[key.Line-1]
+	keyLine := line[:key.Column-1] + key.Value + ":\n"
+	d.splice(key.Line-1, key.Line, append([]string{keyLine}, item...))
+
+	return d.parse()
+}
+
+// ReplaceProfile replaces the profile with the given name, the new profile may have a different name
+func (d *Document) ReplaceProfile(name string, node *yaml.Node) error {
+	current := d.profileNode(name)
+	if current == nil {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	newName := profileName(node)
+	if newName == "" {
+		return fmt.Errorf("profile is missing a name")
+	}
+
+	if newName != name && d.profileNode(newName) != nil {
+		return fmt.Errorf("profile %q already exists", newName)
+	}
+
+	start, end, err := d.itemRange(current)
+	if err != nil {
+		return err
+	}
+
+	item, err := renderItem(node, itemIndent(d.lines[start]))
+	if err != nil {
+		return err
+	}
+
+	d.splice(start, end, item)
+
+	return d.parse()
+}
+
+// RemoveProfile removes the profile with the given name
+func (d *Document) RemoveProfile(name string) error {
+	node := d.profileNode(name)
+	if node == nil {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	start, end, err := d.itemRange(node)
+	if err != nil {
+		return err
+	}
+
+	// the comments directly above the profile belong to it
+	for start > 0 && strings.HasPrefix(strings.TrimSpace(d.lines[start-1]), "#") {
+		start--
+	}
+
+	// don't leave two blank lines or a blank line after the profiles key behind
+	first := d.profiles().Content[0] == node
+	if start > 0 && end < len(d.lines) && strings.TrimSpace(d.lines[end]) == "" &&
+		(first || strings.TrimSpace(d.lines[start-1]) == "") {
+		end++
+	}
+
+	d.splice(start, end, nil)
+
+	return d.parse()
+}
+
+// RenameProfile renames the profile oldName to newName
+func (d *Document) RenameProfile(oldName, newName string) error {
+	node := d.profileNode(oldName)
+	if node == nil {
+		return fmt.Errorf("profile %q not found", oldName)
+	}
+
+	if d.profileNode(newName) != nil {
+		return fmt.Errorf("profile %q already exists", newName)
+	}
+
+	if err := d.setName(node, newName); err != nil {
+		return err
+	}
+
+	return d.parse()
+}
+
+// CopyProfile adds a copy of the profile src named dst
+func (d *Document) CopyProfile(src, dst string) error {
+	node, err := d.Profile(src)
+	if err != nil {
+		return err
+	}
+
+	if err := node.Content[mappingKeyIndex(node, "name")+1].Encode(dst); err != nil {
+		return fmt.Errorf("set profile name: %w", err)
+	}
+
+	return d.AddProfile(node)
+}
+
+// Bytes returns the content of the document
+func (d *Document) Bytes() []byte {
+	return []byte(strings.Join(d.lines, ""))
+}
+
+// Validate runs the same validation as "dpm validate" against the document.
+// The validation runs on a copy of the document's configuration, the global
+// Config struct is restored afterwards.
+func (d *Document) Validate(ctx context.Context) error {
+	cfg, err := parseConfig(d.file, d.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// the profile validation works on the global Config struct
+	previous := profile.Config
+	profile.Config = cfg
+	defer func() { profile.Config = previous }()
+
+	if err := profile.ValidateAllProfiles(ctx); err != nil {
+		return fmt.Errorf("validate profiles: %w", err)
+	}
+
+	return nil
+}
+
+// Save validates the document and writes it back to its file
+func (d *Document) Save(ctx context.Context) error {
+	if err := d.Validate(ctx); err != nil {
+		return err
+	}
+
+	data := d.Bytes()
+
+	mode := os.FileMode(0o600)
+	if info, err := os.Stat(d.file); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := os.WriteFile(d.file, data, mode); err != nil {
+		return fmt.Errorf("write config file: %w", err)
+	}
+
+	return nil
+}
+
+// splice replaces the lines [start, end) with lines
+func (d *Document) splice(start, end int, lines []string) {
+	d.lines = slices.Concat(d.lines[:start], lines, d.lines[end:])
+}
+
+// itemRange returns the lines [start, end) of a profile within the profiles sequence
+func (d *Document) itemRange(item *yaml.Node) (int, int, error) {
+	if item.Kind != yaml.MappingNode || item.Style&yaml.FlowStyle != 0 {
+		return 0, 0, fmt.Errorf("profile in line %d: only block style profiles are supported, please edit the config file manually", item.Line)
+	}
+
+	start := item.Line - 1
+	if !strings.Contains(d.lines[start][:item.Column-1], "-") {
+		return 0, 0, fmt.Errorf("profile in line %d: unsupported list item format, please edit the config file manually", item.Line)
+	}
+
+	return start, lastLine(item), nil
+}
+
+// setName replaces the value of the name field within its line
+func (d *Document) setName(item *yaml.Node, name string) error {
+	value := mappingValue(item, "name")
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("profile in line %d is missing a name", item.Line)
+	}
+
+	encoded, err := yaml.Marshal(name)
+	if err != nil {
+		return fmt.Errorf("encode profile name: %w", err)
+	}
+
+	line := d.lines[value.Line-1][:value.Column-1] + strings.TrimSuffix(string(encoded), "\n")
+	if value.LineComment != "" {
+		line += " " + value.LineComment
+	}
+
+	d.lines[value.Line-1] = line + "\n"
+
+	return nil
+}
+
+// lastLine returns the last line (1-based) used by node and its children
+func lastLine(node *yaml.Node) int {
+	last := node.Line
+
+	if node.Kind == yaml.ScalarNode && (node.Style&(yaml.LiteralStyle|yaml.FoldedStyle)) != 0 {
+		last += strings.Count(strings.TrimSuffix(node.Value, "\n"), "\n") + 1
+	}
+
+	for _, c := range node.Content {
+		last = max(last, lastLine(c))
+	}
+
+	return last
+}
+
+// itemIndent returns the indentation in front of the list item marker
+func itemIndent(line string) string {
+	return line[:strings.Index(line, "-")]
+}
+
+// renderItem encodes a profile node as list item with the given indentation
+func renderItem(node *yaml.Node, indent string) ([]string, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("encode profile: %w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encode profile: %w", err)
+	}
+
+	var lines []string
+	marker := false
+
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		switch {
+		case line == "":
+		case strings.TrimSpace(line) == "":
+			lines = append(lines, line)
+		case !marker && !strings.HasPrefix(line, "#"):
+			// the first field carries the list item marker
+			lines = append(lines, indent+"- "+line)
+			marker = true
+		case !marker:
+			lines = append(lines, indent+line)
+		default:
+			lines = append(lines, indent+"  "+line)
+		}
+	}
+
+	return lines, nil
+}
+
+// ProfileNode converts a profile into a YAML node, empty fields are omitted
+func ProfileNode(p *profile.Profile) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	addScalar(node, "name", p.ProfileName)
+	addScalar(node, "profile", p.Profile)
+
+	if p.ProfileSource.Type != "" {
+		source := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		addScalar(source, "type", p.ProfileSource.Type)
+		addScalar(source, "path", p.ProfileSource.Path)
+		addScalar(source, "name", p.ProfileSource.Name)
+
+		if p.ProfileSource.Git != nil {
+			git := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			addScalar(git, "url", p.ProfileSource.Git.URL)
+			addScalar(git, "ref", p.ProfileSource.Git.Ref)
+			addScalar(git, "path", p.ProfileSource.Git.Path)
+			source.Content = append(source.Content, scalarNode("git"), git)
+		}
+
+		if p.ProfileSource.ConfigMap != nil {
+			cm := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			addScalar(cm, "name", p.ProfileSource.ConfigMap.Name)
+			source.Content = append(source.Content, scalarNode("configMap"), cm)
+		}
+
+		node.Content = append(node.Content, scalarNode("profileSource"), source)
+	}
+
+	addScalar(node, "image", p.Image)
+	addScalar(node, "imagePullPolicy", string(p.ImagePullPolicy))
+	addScalar(node, "namespace", p.Namespace)
+	if p.Namespaces.IsSet() {
+		namespaces := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		addStringList(namespaces, "names", p.Namespaces.Names)
+		addScalar(namespaces, "regex", p.Namespaces.Regex)
+		addStringMap(namespaces, "matchLabels", p.Namespaces.MatchLabels)
+		node.Content = append(node.Content, scalarNode("namespaces"), namespaces)
+	}
+	addScalar(node, "targetContainer", p.TargetContainer)
+	addStringMap(node, "matchLabels", p.MatchLabels)
+	addLabelRequirements(node, "matchExpressions", p.MatchExpressions)
+	addScalar(node, "fieldSelector", p.FieldSelector)
+	addScalar(node, "workload", p.Workload)
+	addScalar(node, "inheritEnvFrom", p.InheritEnvFrom)
+	if p.InheritEnvFilter.IsSet() {
+		filter := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		addStringList(filter, "include", p.InheritEnvFilter.Include)
+		addStringList(filter, "exclude", p.InheritEnvFilter.Exclude)
+		node.Content = append(node.Content, scalarNode("inheritEnvFilter"), filter)
+	}
+	addStringList(node, "contexts", p.Contexts)
+	addStringList(node, "clusters", p.Clusters)
+
+	return node
+}
+
+// mappingKeyIndex returns the index of the key node within a mapping node or -1
+func mappingKeyIndex(node *yaml.Node, key string) int {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return -1
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// mappingValue returns the value node of key within a mapping node or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	idx := mappingKeyIndex(node, key)
+	if idx == -1 {
+		return nil
+	}
+
+	return node.Content[idx+1]
+}
+
+// profileName returns the name of a profile node
+func profileName(node *yaml.Node) string {
+	if name := mappingValue(node, "name"); name != nil {
+		return name.Value
+	}
+
+	return ""
+}
+
+// copyNode returns a deep copy of node
+func copyNode(node *yaml.Node) *yaml.Node {
+	cp := *node
+	cp.Content = make([]*yaml.Node, len(node.Content))
+
+	for i, c := range node.Content {
+		cp.Content[i] = copyNode(c)
+	}
+
+	return &cp
+}
+
+func addScalar(node *yaml.Node, key, value string) {
+	if value == "" {
+		return
+	}
+
+	node.Content = append(node.Content, scalarNode(key), scalarNode(value))
+}
+
+func addStringList(node *yaml.Node, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, v := range values {
+		list.Content = append(list.Content, scalarNode(v))
+	}
+
+	node.Content = append(node.Content, scalarNode(key), list)
+}
+
+func addStringMap(node *yaml.Node, key string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, k := range keys {
+		m.Content = append(m.Content, scalarNode(k), scalarNode(values[k]))
+	}
+
+	node.Content = append(node.Content, scalarNode(key), m)
+}
+
+func addLabelRequirements(node *yaml.Node, key string, requirements []profile.LabelRequirement) {
+	if len(requirements) == 0 {
+		return
+	}
+
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, r := range requirements {
+		m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		addScalar(m, "key", r.Key)
+		addScalar(m, "operator", r.Operator)
+		addStringList(m, "values", r.Values)
+		list.Content = append(list.Content, m)
+	}
+
+	node.Content = append(node.Content, scalarNode(key), list)
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,329 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+const testDocument = `# debug profiles
+kubectlPath: /usr/local/bin/kubectl
+
+profiles:
+  # network debugging
+  - name: netadmin
+    profileSource:
+      type: builtin
+      name: netadmin # built-in
+    image: nicolaka/netshoot:v0.13
+
+  # system debugging
+  - name: sysadmin
+    profileSource:
+      type: builtin
+      name: sysadmin
+    matchLabels:
+      app: web
+
+style:
+  headerForegroundColor: "#ffffaf"
+`
+
+func TestDocument(t *testing.T) {
+	newProfile := &profile.Profile{
+		ProfileName:   "general",
+		ProfileSource: profile.ProfileSourceConfig{Type: profile.SourceTypeBuiltIn, Name: "general"},
+		Image:         "busybox",
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		change  func(d *Document) error
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "add profile",
+			config: testDocument,
+			change: func(d *Document) error { return d.AddProfile(ProfileNode(newProfile)) },
+			want: `# debug profiles
+kubectlPath: /usr/local/bin/kubectl
+
+profiles:
+  # network debugging
+  - name: netadmin
+    profileSource:
+      type: builtin
+      name: netadmin # built-in
+    image: nicolaka/netshoot:v0.13
+
+  # system debugging
+  - name: sysadmin
+    profileSource:
+      type: builtin
+      name: sysadmin
+    matchLabels:
+      app: web
+  - name: general
+    profileSource:
+      type: builtin
+      name: general
+    image: busybox
+
+style:
+  headerForegroundColor: "#ffffaf"
+`,
+		},
+		{
+			name:   "add profile to empty profiles",
+			config: "kubectlPath: kubectl\nprofiles: [] # none yet\n",
+			change: func(d *Document) error { return d.AddProfile(ProfileNode(newProfile)) },
+			want: `kubectlPath: kubectl
+profiles:
+  - name: general
+    profileSource:
+      type: builtin
+      name: general
+    image: busybox
+`,
+		},
+		{
+			name:   "add profile without profiles",
+			config: "kubectlPath: kubectl",
+			change: func(d *Document) error { return d.AddProfile(ProfileNode(newProfile)) },
+			want: `kubectlPath: kubectl
+profiles:
+  - name: general
+    profileSource:
+      type: builtin
+      name: general
+    image: busybox
+`,
+		},
+		{
+			name:    "add existing profile",
+			config:  testDocument,
+			change:  func(d *Document) error { return d.AddProfile(ProfileNode(&profile.Profile{ProfileName: "netadmin"})) },
+			wantErr: true,
+		},
+		{
+			name:   "remove profile",
+			config: testDocument,
+			change: func(d *Document) error { return d.RemoveProfile("netadmin") },
+			want: `# debug profiles
+kubectlPath: /usr/local/bin/kubectl
+
+profiles:
+  # system debugging
+  - name: sysadmin
+    profileSource:
+      type: builtin
+      name: sysadmin
+    matchLabels:
+      app: web
+
+style:
+  headerForegroundColor: "#ffffaf"
+`,
+		},
+		{
+			name:    "remove unknown profile",
+			config:  testDocument,
+			change:  func(d *Document) error { return d.RemoveProfile("unknown") },
+			wantErr: true,
+		},
+		{
+			name:   "rename profile",
+			config: testDocument,
+			change: func(d *Document) error { return d.RenameProfile("sysadmin", "sys admin") },
+			want: `# debug profiles
+kubectlPath: /usr/local/bin/kubectl
+
+profiles:
+  # network debugging
+  - name: netadmin
+    profileSource:
+      type: builtin
+      name: netadmin # built-in
+    image: nicolaka/netshoot:v0.13
+
+  # system debugging
+  - name: sys admin
+    profileSource:
+      type: builtin
+      name: sysadmin
+    matchLabels:
+      app: web
+
+style:
+  headerForegroundColor: "#ffffaf"
+`,
+		},
+		{
+			name:    "rename to existing profile",
+			config:  testDocument,
+			change:  func(d *Document) error { return d.RenameProfile("sysadmin", "netadmin") },
+			wantErr: true,
+		},
+		{
+			name:   "copy profile",
+			config: testDocument,
+			change: func(d *Document) error { return d.CopyProfile("netadmin", "netadmin-copy") },
+			want: `# debug profiles
+kubectlPath: /usr/local/bin/kubectl
+
+profiles:
+  # network debugging
+  - name: netadmin
+    profileSource:
+      type: builtin
+      name: netadmin # built-in
+    image: nicolaka/netshoot:v0.13
+
+  # system debugging
+  - name: sysadmin
+    profileSource:
+      type: builtin
+      name: sysadmin
+    matchLabels:
+      app: web
+  - name: netadmin-copy
+    profileSource:
+      type: builtin
+      name: netadmin # built-in
+    image: nicolaka/netshoot:v0.13
+
+style:
+  headerForegroundColor: "#ffffaf"
+`,
+		},
+		{
+			name:   "replace profile",
+			config: testDocument,
+			change: func(d *Document) error { return d.ReplaceProfile("netadmin", ProfileNode(newProfile)) },
+			want: `# debug profiles
+kubectlPath: /usr/local/bin/kubectl
+
+profiles:
+  # network debugging
+  - name: general
+    profileSource:
+      type: builtin
+      name: general
+    image: busybox
+
+  # system debugging
+  - name: sysadmin
+    profileSource:
+      type: builtin
+      name: sysadmin
+    matchLabels:
+      app: web
+
+style:
+  headerForegroundColor: "#ffffaf"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				profile.Config = profile.CustomDebugProfile{}
+			})
+
+			file := filepath.Join(t.TempDir(), "debug-profiles.yaml")
+			if err := os.WriteFile(file, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			doc, err := LoadDocument(file)
+			if err != nil {
+				t.Fatalf("LoadDocument() error = %v", err)
+			}
+
+			err = tt.change(doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("change error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if err := doc.Save(context.Background()); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			got, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("config =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_SaveInvalid(t *testing.T) {
+	t.Cleanup(func() {
+		profile.Config = profile.CustomDebugProfile{}
+	})
+
+	file := filepath.Join(t.TempDir(), "debug-profiles.yaml")
+	if err := os.WriteFile(file, []byte(testDocument), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := LoadDocument(file)
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+
+	invalid := &profile.Profile{
+		ProfileName:   "invalid",
+		ProfileSource: profile.ProfileSourceConfig{Type: profile.SourceTypeBuiltIn, Name: "unknown"},
+	}
+	if err := doc.AddProfile(ProfileNode(invalid)); err != nil {
+		t.Fatalf("AddProfile() error = %v", err)
+	}
+
+	if err := doc.Save(context.Background()); err == nil {
+		t.Fatal("Save() expected an error for an invalid profile")
+	}
+
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != testDocument {
+		t.Errorf("invalid config was written:\n%s", got)
+	}
+}
+
+func TestDocument_ValidateKeepsConfig(t *testing.T) {
+	loaded := profile.CustomDebugProfile{Profiles: []profile.Profile{{ProfileName: "loaded"}}}
+	profile.Config = loaded
+	t.Cleanup(func() {
+		profile.Config = profile.CustomDebugProfile{}
+	})
+
+	doc, err := ParseDocument("debug-profiles.yaml", []byte(testDocument))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	if len(profile.Config.Profiles) != 1 || profile.Config.Profiles[0].ProfileName != "loaded" {
+		t.Errorf("Validate() changed the global config: %+v", profile.Config.Profiles)
+	}
+}
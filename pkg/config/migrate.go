@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+// Migration describes the changes of a single migrated profile
+type Migration struct {
+	ProfileName string
+	// SourceType is the type of the new profileSource, empty if the legacy field got dropped
+	SourceType string
+	Value      string
+	// Dropped is set if the legacy field got removed because profileSource was already set
+	Dropped bool
+}
+
+// MigrateLegacyProfiles converts all legacy "profile" entries of the configuration
+// into "profileSource" entries. Built-in kubectl profile names become a builtin source,
+// everything else becomes a file source. Only the lines of the legacy fields are
+// rewritten, so comments, blank lines and the order of fields are preserved.
+func MigrateLegacyProfiles(data []byte) ([]byte, []Migration, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return data, nil, nil
+	}
+
+	profiles := mappingValue(doc.Content[0], "profiles")
+	if profiles == nil || profiles.Kind != yaml.SequenceNode {
+		return data, nil, nil
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+
+	var migrations []Migration
+
+	// rewrite from bottom to top to keep the line numbers of the remaining profiles valid
+	for i := len(profiles.Content) - 1; i >= 0; i-- {
+		m, ok, err := migrateProfileNode(profiles.Content[i], lines)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			lines = m.lines
+			migrations = append([]Migration{m.Migration}, migrations...)
+		}
+	}
+
+	return []byte(strings.Join(lines, "")), migrations, nil
+}
+
+type lineMigration struct {
+	Migration
+	lines []string
+}
+
+// migrateProfileNode replaces the line of the legacy profile key of a single profile mapping
+func migrateProfileNode(p *yaml.Node, lines []string) (lineMigration, bool, error) {
+	keyIdx := mappingKeyIndex(p, "profile")
+	if keyIdx == -1 {
+		return lineMigration{}, false, nil
+	}
+
+	key, value := p.Content[keyIdx], p.Content[keyIdx+1]
+
+	m := lineMigration{Migration: Migration{Value: value.Value}}
+	if name := mappingValue(p, "name"); name != nil {
+		m.ProfileName = name.Value
+	}
+
+	line := lines[key.Line-1]
+	prefix := line[:key.Column-1]
+
+	if key.Line != value.Line || value.Kind != yaml.ScalarNode || strings.Trim(prefix, " -") != "" {
+		return m, false, fmt.Errorf("profile %q: can't migrate the profile field in line %d, please migrate it manually",
+			m.ProfileName, key.Line)
+	}
+
+	// everything after "profile:" - the value as written, including quotes and comments
+	rest := strings.TrimPrefix(line[key.Column-1:], key.Value)
+	rest = strings.TrimPrefix(rest, ":")
+	indent := strings.Repeat(" ", len(prefix))
+
+	var replacement []string
+
+	switch {
+	case mappingValue(p, "profileSource") != nil:
+		// an existing profileSource always took precedence, the legacy field was ignored
+		m.Dropped = true
+		if strings.TrimSpace(prefix) != "" {
+			// the legacy field starts the list item, keep the item marker on the next field
+			return m, false, fmt.Errorf("profile %q: can't drop the profile field in line %d, please migrate it manually",
+				m.ProfileName, key.Line)
+		}
+	case slices.Contains(profile.BuiltInProfileNames(), value.Value):
+		m.SourceType = profile.SourceTypeBuiltIn
+		replacement = []string{
+			prefix + "profileSource:\n",
+			indent + "  type: " + profile.SourceTypeBuiltIn + "\n",
+			indent + "  name:" + rest,
+		}
+	default:
+		m.SourceType = profile.SourceTypeFile
+		replacement = []string{
+			prefix + "profileSource:\n",
+			indent + "  type: " + profile.SourceTypeFile + "\n",
+			indent + "  path:" + rest,
+		}
+	}
+
+	m.lines = slices.Concat(lines[:key.Line-1], replacement, lines[key.Line:])
+
+	return m, true, nil
+}
+
+// Diff returns a unified diff between the original and the migrated configuration
+func Diff(file string, original, migrated []byte) (string, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(original)),
+		B:        difflib.SplitLines(string(migrated)),
+		FromFile: file,
+		ToFile:   file,
+		Context:  3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("generate diff: %w", err)
+	}
+
+	return diff, nil
+}
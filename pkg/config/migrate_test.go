@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrateLegacyProfiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         string
+		wantConfig     string
+		wantMigrations []Migration
+		wantErr        bool
+	}{
+		{
+			name: "migrate builtin and file profiles",
+			config: `# my profiles
+profiles:
+  # network debugging
+  - name: netadmin
+    profile: netadmin # built-in
+
+    image: nicolaka/netshoot:v0.13
+  - name: app
+    profile: "$HOME/profiles/app.json"
+    image: busybox
+`,
+			wantConfig: `# my profiles
+profiles:
+  # network debugging
+  - name: netadmin
+    profileSource:
+      type: builtin
+      name: netadmin # built-in
+
+    image: nicolaka/netshoot:v0.13
+  - name: app
+    profileSource:
+      type: file
+      path: "$HOME/profiles/app.json"
+    image: busybox
+`,
+			wantMigrations: []Migration{
+				{ProfileName: "netadmin", SourceType: "builtin", Value: "netadmin"},
+				{ProfileName: "app", SourceType: "file", Value: "$HOME/profiles/app.json"},
+			},
+		},
+		{
+			name: "legacy field starts the list item",
+			config: `profiles:
+  - profile: sysadmin
+    name: sysadmin
+`,
+			wantConfig: `profiles:
+  - profileSource:
+      type: builtin
+      name: sysadmin
+    name: sysadmin
+`,
+			wantMigrations: []Migration{
+				{ProfileName: "sysadmin", SourceType: "builtin", Value: "sysadmin"},
+			},
+		},
+		{
+			name: "drop legacy field if profileSource is set",
+			config: `profiles:
+  - name: app
+    profile: app.json
+    profileSource:
+      type: file
+      path: other.json
+`,
+			wantConfig: `profiles:
+  - name: app
+    profileSource:
+      type: file
+      path: other.json
+`,
+			wantMigrations: []Migration{
+				{ProfileName: "app", Value: "app.json", Dropped: true},
+			},
+		},
+		{
+			name: "nothing to migrate",
+			config: `profiles:
+  - name: app
+    profileSource:
+      type: file
+      path: app.json
+`,
+			wantConfig: `profiles:
+  - name: app
+    profileSource:
+      type: file
+      path: app.json
+`,
+		},
+		{
+			name: "flow style is not supported",
+			config: `profiles:
+  - {name: app, profile: app.json}
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, migrations, err := MigrateLegacyProfiles([]byte(tt.config))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MigrateLegacyProfiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(got) != tt.wantConfig {
+				t.Errorf("MigrateLegacyProfiles() config =\n%s\nwant\n%s", got, tt.wantConfig)
+			}
+			if !reflect.DeepEqual(migrations, tt.wantMigrations) {
+				t.Errorf("MigrateLegacyProfiles() migrations = %v, want %v", migrations, tt.wantMigrations)
+			}
+			if err := ValidateSchema("config.yaml", got); err != nil {
+				t.Errorf("migrated config is invalid: %v", err)
+			}
+		})
+	}
+}
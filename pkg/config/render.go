@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"text/template"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+var configTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`# kubectl-dpm configuration
+# documentation: https://github.com/bavarianbidi/kubectl-dpm#configuration
+#
+# print the JSON Schema of this file with "kubectl dpm config schema"
+
+# path to the kubectl binary, only used if dpm doesn't run as kubectl plugin
+kubectlPath: {{ quote .KubectlPath }}
+
+{{ if .Skeletons -}}
+profiles:
+{{- range .Skeletons }}
+  # generated from {{ .Origin }}
+  - name: {{ quote .Profile.ProfileName }}
+    profileSource:
+      type: {{ .Profile.ProfileSource.Type }}
+      path: {{ quote .Profile.ProfileSource.Path }}
+    # image of the ephemeral debug container
+    image: {{ quote .Profile.Image }}
+    imagePullPolicy: {{ .Profile.ImagePullPolicy }}
+    namespace: {{ quote .Profile.Namespace }}
+    targetContainer: {{ quote .Profile.TargetContainer }}
+{{- if .Profile.MatchLabels }}
+    # the target pod is selected by these labels
+    matchLabels:
+{{- range $key, $value := .Profile.MatchLabels }}
+      {{ quote $key }}: {{ quote $value }}
+{{- end }}
+{{- end }}
+{{- if .Profile.MatchExpressions }}
+    # the target pod is selected by these label expressions
+    matchExpressions:
+{{- range .Profile.MatchExpressions }}
+      - key: {{ quote .Key }}
+        operator: {{ .Operator }}
+{{- if .Values }}
+        values:
+{{- range .Values }}
+          - {{ quote . }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{ else -}}
+profiles: []
+# - name: netadmin
+#   profileSource:
+#     type: builtin
+#     name: netadmin
+#   image: nicolaka/netshoot:v0.13
+#   namespace: default
+#   matchLabels:
+#     app: myapp
+{{ end -}}
+`))
+
+// RenderConfig renders a commented configuration file with the given kubectl path and profiles
+func RenderConfig(kubectlPath string, skeletons []profile.Skeleton) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := configTemplate.Execute(&buf, struct {
+		KubectlPath string
+		Skeletons   []profile.Skeleton
+	}{
+		KubectlPath: kubectlPath,
+		Skeletons:   skeletons,
+	}); err != nil {
+		return nil, fmt.Errorf("render config: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func TestRenderConfig(t *testing.T) {
+	skeleton := profile.Skeleton{
+		Origin: "deployment/webapp",
+		Profile: profile.Profile{
+			ProfileName: "webapp",
+			ProfileSource: profile.ProfileSourceConfig{
+				Type: profile.SourceTypeFile,
+				Path: "/home/user/.kube-dpm/profiles/webapp.json",
+			},
+			Image:           "nicolaka/netshoot:v0.13",
+			Namespace:       "default",
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			TargetContainer: "webapp",
+			MatchLabels: map[string]string{
+				"app.kubernetes.io/name": "webapp",
+				"run":                    "webapp",
+			},
+			MatchExpressions: []profile.LabelRequirement{
+				{Key: "track", Operator: "In", Values: []string{"stable", "canary"}},
+				{Key: "legacy", Operator: "DoesNotExist"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		kubectlPath string
+		skeletons   []profile.Skeleton
+		want        profile.CustomDebugProfile
+	}{
+		{
+			name:        "without profiles",
+			kubectlPath: "/usr/local/bin/kubectl",
+			want: profile.CustomDebugProfile{
+				KubectlPath: "/usr/local/bin/kubectl",
+				Profiles:    []profile.Profile{},
+			},
+		},
+		{
+			name:        "with profiles",
+			kubectlPath: "/usr/local/bin/kubectl",
+			skeletons:   []profile.Skeleton{skeleton},
+			want: profile.CustomDebugProfile{
+				KubectlPath: "/usr/local/bin/kubectl",
+				Profiles:    []profile.Profile{skeleton.Profile},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				ConfigurationFile = ""
+				profile.Config = profile.CustomDebugProfile{}
+			})
+
+			data, err := RenderConfig(tt.kubectlPath, tt.skeletons)
+			if err != nil {
+				t.Fatalf("RenderConfig() error = %v", err)
+			}
+
+			// the rendered config must be loadable
+			ConfigurationFile = filepath.Join(t.TempDir(), "debug-profiles.yaml")
+			if err := os.WriteFile(ConfigurationFile, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := GenerateConfig(); err != nil {
+				t.Fatalf("GenerateConfig() error = %v\n%s", err, data)
+			}
+
+			if !reflect.DeepEqual(profile.Config, tt.want) {
+				t.Errorf("expected: %+v, got: %+v", tt.want, profile.Config)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaJSON is the published JSON Schema of the configuration file
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+// Schema returns the JSON Schema of the configuration file
+func Schema() []byte {
+	return schemaJSON
+}
+
+// jsonSchema is the subset of JSON Schema which is used by schema.json
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Required             []string               `json:"required"`
+	Enum                 []string               `json:"enum"`
+	MinLength            int                    `json:"minLength"`
+	Pattern              string                 `json:"pattern"`
+	Defs                 map[string]*jsonSchema `json:"$defs"`
+}
+
+// SchemaError describes a single violation of the configuration schema
+type SchemaError struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.Path, e.Message)
+}
+
+// schemaValidator walks a YAML document and collects all schema violations
+type schemaValidator struct {
+	file   string
+	root   *jsonSchema
+	errors []error
+}
+
+// ValidateSchema strictly validates the YAML configuration data against the
+// configuration schema. Unknown fields, wrong types, missing required fields and
+// invalid enum values or patterns are reported with file, line and column.
+func ValidateSchema(file string, data []byte) error {
+	root := &jsonSchema{}
+	if err := json.Unmarshal(schemaJSON, root); err != nil {
+		return fmt.Errorf("parse configuration schema: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse %s: %w", file, err)
+	}
+
+	// empty documents are valid
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+
+	v := &schemaValidator{file: file, root: root}
+	v.validate(root, doc.Content[0], "$")
+
+	return errors.Join(v.errors...)
+}
+
+func (v *schemaValidator) addError(node *yaml.Node, path, format string, args ...any) {
+	v.errors = append(v.errors, &SchemaError{
+		File:    v.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *schemaValidator) resolve(s *jsonSchema) *jsonSchema {
+	if s.Ref == "" {
+		return s
+	}
+
+	name := strings.TrimPrefix(s.Ref, "#/$defs/")
+	if def, ok := v.root.Defs[name]; ok {
+		return def
+	}
+
+	return s
+}
+
+func (v *schemaValidator) validate(s *jsonSchema, node *yaml.Node, path string) {
+	s = v.resolve(s)
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	// explicit null values are treated like missing fields
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		v.validateObject(s, node, path)
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			v.addError(node, path, "expected a list")
+			return
+		}
+		if s.Items == nil {
+			return
+		}
+		for i, item := range node.Content {
+			v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			v.addError(node, path, "expected a string")
+			return
+		}
+		if len(node.Value) < s.MinLength {
+			v.addError(node, path, "must not be empty")
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, node.Value) {
+			v.addError(node, path, "invalid value %q (valid values: %s)", node.Value, strings.Join(s.Enum, ", "))
+		}
+		if s.Pattern != "" {
+			if ok, err := regexp.MatchString(s.Pattern, node.Value); err != nil {
+				v.addError(node, path, "invalid pattern %q in schema: %v", s.Pattern, err)
+			} else if !ok {
+				v.addError(node, path, "invalid value %q (must match %s)", node.Value, s.Pattern)
+			}
+		}
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			v.addError(node, path, "expected a boolean")
+		}
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			v.addError(node, path, "expected an integer")
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(s *jsonSchema, node *yaml.Node, path string) {
+	if node.Kind != yaml.MappingNode {
+		v.addError(node, path, "expected an object")
+		return
+	}
+
+	// additionalProperties is either false or a schema for all unknown keys
+	var additional *jsonSchema
+	additionalAllowed := len(s.AdditionalProperties) == 0
+	if len(s.AdditionalProperties) > 0 && string(s.AdditionalProperties) != "false" {
+		additional = &jsonSchema{}
+		if err := json.Unmarshal(s.AdditionalProperties, additional); err == nil {
+			additionalAllowed = true
+		}
+	}
+
+	seen := map[string]bool{}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		fieldPath := path + "." + key.Value
+		seen[key.Value] = true
+
+		property, ok := s.Properties[key.Value]
+		switch {
+		case ok:
+			v.validate(property, value, fieldPath)
+		case additional != nil:
+			v.validate(additional, value, fieldPath)
+		case !additionalAllowed:
+			v.addError(key, fieldPath, "unknown field %q%s", key.Value, suggestField(key.Value, s.Properties))
+		}
+	}
+
+	for _, required := range s.Required {
+		if !seen[required] {
+			v.addError(node, path, "missing required field %q", required)
+		}
+	}
+}
+
+// suggestField returns a hint for a misspelled field name, e.g. matchLabel vs. matchLabels
+func suggestField(field string, properties map[string]*jsonSchema) string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// prefer names which only differ in case
+	for _, name := range names {
+		if strings.EqualFold(name, field) {
+			return fmt.Sprintf(" (did you mean %q?)", name)
+		}
+	}
+
+	for _, name := range names {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(field)) ||
+			strings.HasPrefix(strings.ToLower(field), strings.ToLower(name)) {
+			return fmt.Sprintf(" (did you mean %q?)", name)
+		}
+	}
+
+	return ""
+}
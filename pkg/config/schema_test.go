@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bavarianbidi/kubectl-dpm/pkg/profile"
+)
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		wantErrors []string
+	}{
+		{
+			name: "valid config",
+			config: `
+kubectlPath: /usr/local/bin/kubectl
+profiles:
+  - name: profile1
+    profileSource:
+      type: git
+      git:
+        url: https://github.com/org/repo
+        path: profile.json
+    imagePullPolicy: Always
+    matchLabels:
+      app.kubernetes.io/instance: app
+    contexts:
+      - monitoring-*
+style:
+  headerForegroundColor: "#ffffaf"
+`,
+		},
+		{
+			name:   "empty config",
+			config: "",
+		},
+		{
+			name: "unknown fields",
+			config: `
+profiles:
+  - name: profile1
+    profilesource:
+      type: file
+      path: profile.json
+    matchLabel:
+      app: test
+`,
+			wantErrors: []string{
+				`config.yaml:4:5: $.profiles[0].profilesource: unknown field "profilesource" (did you mean "profileSource"?)`,
+				`config.yaml:7:5: $.profiles[0].matchLabel: unknown field "matchLabel" (did you mean "matchLabels"?)`,
+			},
+		},
+		{
+			name: "unknown top level field",
+			config: `
+prof:
+  - name: profile1
+`,
+			wantErrors: []string{
+				`config.yaml:2:1: $.prof: unknown field "prof" (did you mean "profiles"?)`,
+			},
+		},
+		{
+			name: "constraint violations",
+			config: `
+profiles:
+  - profile: netadmin
+    imagePullPolicy: Sometimes
+  - name: profile2
+    profileSource:
+      type: svn
+    matchLabels:
+      app:
+        - test
+`,
+			wantErrors: []string{
+				`config.yaml:4:22: $.profiles[0].imagePullPolicy: invalid value "Sometimes" (valid values: Always, IfNotPresent, Never)`,
+				`config.yaml:3:5: $.profiles[0]: missing required field "name"`,
+				`config.yaml:7:13: $.profiles[1].profileSource.type: invalid value "svn" (valid values: file, builtin, git, configmap)`,
+				`config.yaml:10:9: $.profiles[1].matchLabels.app: expected a string`,
+			},
+		},
+		{
+			name: "pattern violations",
+			config: `
+profiles:
+  - name: profile1
+    collect:
+      - /var/log
+      - var/log
+`,
+			wantErrors: []string{
+				`config.yaml:6:9: $.profiles[0].collect[1]: invalid value "var/log" (must match ^/)`,
+			},
+		},
+		{
+			name: "workload pattern violations",
+			config: `
+profiles:
+  - name: profile1
+    workload: deployment/webapp
+  - name: profile2
+    workload: webapp
+`,
+			wantErrors: []string{
+				`config.yaml:6:15: $.profiles[1].workload: invalid value "webapp" (must match ^[A-Za-z]+/.+$)`,
+			},
+		},
+		{
+			name: "duration pattern violations",
+			config: `
+sessions:
+  limits:
+    maxDuration: 1h30m
+    idleTimeout: 15 minutes
+profiles:
+  - name: profile1
+    limits:
+      maxDuration: 2d
+`,
+			wantErrors: []string{
+				`config.yaml:5:18: $.sessions.limits.idleTimeout: invalid value "15 minutes" (must match ^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$)`,
+				`config.yaml:9:20: $.profiles[0].limits.maxDuration: invalid value "2d" (must match ^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSchema("config.yaml", []byte(tt.config))
+
+			var got []string
+			if err != nil {
+				for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+					var schemaErr *SchemaError
+					if !errors.As(e, &schemaErr) {
+						t.Fatalf("unexpected error type %T: %v", e, e)
+					}
+					got = append(got, schemaErr.Error())
+				}
+			}
+
+			if !slices.Equal(got, tt.wantErrors) {
+				t.Errorf("ValidateSchema() errors =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.wantErrors, "\n"))
+			}
+		})
+	}
+}
+
+// TestSchemaMatchesConfigTypes ensures schema.json stays in sync with the
+// configuration types, the required fields of the schema must be exactly the
+// fields with a validate:"required" struct tag
+func TestSchemaMatchesConfigTypes(t *testing.T) {
+	root := &jsonSchema{}
+	if err := json.Unmarshal(Schema(), root); err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+
+	v := &schemaValidator{root: root}
+	compareSchema(t, v, root, reflect.TypeOf(profile.CustomDebugProfile{}), "$")
+}
+
+func compareSchema(t *testing.T, v *schemaValidator, s *jsonSchema, typ reflect.Type, path string) {
+	t.Helper()
+
+	s = v.resolve(s)
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if s.Type != "object" {
+			t.Errorf("%s: schema type %q, want object", path, s.Type)
+			return
+		}
+
+		keys := map[string]bool{}
+		required := map[string]bool{}
+
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			key := field.Tag.Get("koanf")
+			if key == "" || !field.IsExported() {
+				continue
+			}
+			keys[key] = true
+			required[key] = field.Tag.Get("validate") == "required"
+
+			property, ok := s.Properties[key]
+			if !ok {
+				t.Errorf("%s.%s: missing in schema", path, key)
+				continue
+			}
+
+			if field.Tag.Get("validate") == "required" && !slices.Contains(s.Required, key) {
+				t.Errorf("%s.%s: required by struct tag but not by schema", path, key)
+			}
+
+			compareSchema(t, v, property, field.Type, path+"."+key)
+		}
+
+		for key := range s.Properties {
+			if !keys[key] {
+				t.Errorf("%s.%s: in schema but not in %s", path, key, typ.Name())
+			}
+		}
+
+		for _, key := range s.Required {
+			if !required[key] {
+				t.Errorf("%s.%s: required by schema but not by struct tag", path, key)
+			}
+		}
+	case reflect.Slice:
+		if s.Type != "array" || s.Items == nil {
+			t.Errorf("%s: schema type %q, want array with items", path, s.Type)
+			return
+		}
+		compareSchema(t, v, s.Items, typ.Elem(), path+"[]")
+	case reflect.Map:
+		if s.Type != "object" {
+			t.Errorf("%s: schema type %q, want object", path, s.Type)
+		}
+	case reflect.String:
+		if s.Type != "string" {
+			t.Errorf("%s: schema type %q, want string", path, s.Type)
+		}
+	case reflect.Bool:
+		if s.Type != "boolean" {
+			t.Errorf("%s: schema type %q, want boolean", path, s.Type)
+		}
+	case reflect.Int, reflect.Int64:
+		if s.Type != "integer" && s.Type != "string" {
+			t.Errorf("%s: schema type %q, want integer", path, s.Type)
+		}
+	}
+}
+
+func TestSchemaMatchesLintRules(t *testing.T) {
+	root := &jsonSchema{}
+	if err := json.Unmarshal(Schema(), root); err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+
+	rules := root.Defs["lint"].Properties["rules"].Properties
+
+	var names []string
+	for _, rule := range profile.LintRules {
+		names = append(names, rule.Name)
+		if _, ok := rules[rule.Name]; !ok {
+			t.Errorf("lint rule %q missing in schema", rule.Name)
+		}
+	}
+
+	for name := range rules {
+		if !slices.Contains(names, name) {
+			t.Errorf("lint rule %q in schema but not in profile.LintRules", name)
+		}
+	}
+}
+
+// TestSchemaPatterns ensures every pattern of schema.json compiles, an invalid
+// pattern would reject every value of its field
+func TestSchemaPatterns(t *testing.T) {
+	root := &jsonSchema{}
+	if err := json.Unmarshal(schemaJSON, root); err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+
+	var walk func(s *jsonSchema, path string)
+	walk = func(s *jsonSchema, path string) {
+		if s == nil {
+			return
+		}
+		if s.Pattern != "" {
+			if _, err := regexp.Compile(s.Pattern); err != nil {
+				t.Errorf("%s: invalid pattern %q: %v", path, s.Pattern, err)
+			}
+		}
+		for name, p := range s.Properties {
+			walk(p, path+"."+name)
+		}
+		for name, d := range s.Defs {
+			walk(d, "$defs."+name)
+		}
+		walk(s.Items, path+"[]")
+	}
+	walk(root, "$")
+}
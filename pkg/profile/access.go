@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// AccessCheck is a permission a debug session needs
+type AccessCheck struct {
+	Verb        string
+	Resource    string
+	Subresource string
+	Namespace   string
+	Name        string // empty for all objects of the resource
+	// Purpose is what the permission is needed for
+	Purpose string
+}
+
+func (a AccessCheck) String() string {
+	resource := a.Resource
+	if a.Subresource != "" {
+		resource += "/" + a.Subresource
+	}
+	if a.Name != "" {
+		resource += " " + a.Name
+	}
+	return fmt.Sprintf("%s %s in namespace %q", a.Verb, resource, a.Namespace)
+}
+
+// AccessResult is the result of an AccessCheck
+type AccessResult struct {
+	AccessCheck
+	Allowed bool
+	// Reason is the reason of the authorizer, if any
+	Reason string
+}
+
+// MissingAccessError lists the permissions a debug session needs but the user doesn't have
+type MissingAccessError struct {
+	Missing []AccessResult
+}
+
+func (e *MissingAccessError) Error() string {
+	msgs := make([]string, 0, len(e.Missing))
+	for _, m := range e.Missing {
+		msgs = append(msgs, fmt.Sprintf("cannot %s (needed to %s)", m.AccessCheck, m.Purpose))
+	}
+	return "missing permissions: " + strings.Join(msgs, "; ")
+}
+
+// RequiredAccess returns the permissions needed to debug a pod of the namespace with the profile.
+// pod is the name of the target pod, empty if it gets selected by a workload or the matchLabels of the profile.
+func RequiredAccess(p *Profile, namespace, pod string) []AccessCheck {
+	var checks []AccessCheck
+
+	if p.ProfileSource.Type == SourceTypeConfigMap && p.ProfileSource.ConfigMap != nil {
+		checks = append(checks, AccessCheck{
+			Verb: "get", Resource: "configmaps", Namespace: p.Namespace, Name: p.ProfileSource.ConfigMap.Name,
+			Purpose: "read the profile spec",
+		})
+	}
+
+	if pod == "" {
+		checks = append(checks, AccessCheck{
+			Verb: "list", Resource: "pods", Namespace: namespace,
+			Purpose: "find the target pod by the workload or the selector of the profile",
+		})
+	}
+
+	if Config.Sessions.Annotate {
+		checks = append(checks, AccessCheck{
+			Verb: "patch", Resource: "pods", Namespace: namespace, Name: pod,
+			Purpose: "annotate the target pod with the debug session",
+		})
+	}
+
+	var execPurposes []string
+	if len(p.Collect) > 0 {
+		execPurposes = append(execPurposes, "collect files from the debug container")
+	}
+	if limits := p.SessionLimits(); limits.Active() && limits.Kill {
+		execPurposes = append(execPurposes, "kill the debug container at the session limits")
+	}
+	if len(execPurposes) > 0 {
+		checks = append(checks, AccessCheck{
+			Verb: "create", Resource: "pods", Subresource: "exec", Namespace: namespace, Name: pod,
+			Purpose: strings.Join(execPurposes, " and "),
+		})
+	}
+
+	return append(checks,
+		AccessCheck{
+			Verb: "get", Resource: "pods", Namespace: namespace, Name: pod,
+			Purpose: "read the target pod",
+		},
+		AccessCheck{
+			Verb: "patch", Resource: "pods", Subresource: "ephemeralcontainers", Namespace: namespace, Name: pod,
+			Purpose: "add the debug container",
+		},
+		AccessCheck{
+			Verb: "create", Resource: "pods", Subresource: "attach", Namespace: namespace, Name: pod,
+			Purpose: "attach to the debug container",
+		},
+	)
+}
+
+// CheckAccess runs a SelfSubjectAccessReview for every check
+func CheckAccess(ctx context.Context, client authorizationv1client.SelfSubjectAccessReviewsGetter, checks []AccessCheck) ([]AccessResult, error) {
+	results := make([]AccessResult, 0, len(checks))
+
+	for _, c := range checks {
+		review, err := client.SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   c.Namespace,
+					Verb:        c.Verb,
+					Resource:    c.Resource,
+					Subresource: c.Subresource,
+					Name:        c.Name,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("check access to %s: %w", c, err)
+		}
+
+		reason := review.Status.Reason
+		if review.Status.EvaluationError != "" {
+			reason = strings.TrimSpace(reason + " " + review.Status.EvaluationError)
+		}
+
+		results = append(results, AccessResult{
+			AccessCheck: c,
+			Allowed:     review.Status.Allowed,
+			Reason:      reason,
+		})
+	}
+
+	return results, nil
+}
+
+// Preflight checks that the user has all permissions needed to debug a pod of the
+// namespace with the profile and returns a *MissingAccessError otherwise
+func Preflight(ctx context.Context, client authorizationv1client.SelfSubjectAccessReviewsGetter, p *Profile, namespace, pod string) error {
+	results, err := CheckAccess(ctx, client, RequiredAccess(p, namespace, pod))
+	if err != nil {
+		return err
+	}
+
+	missing := &MissingAccessError{}
+	for _, r := range results {
+		if !r.Allowed {
+			missing.Missing = append(missing.Missing, r)
+		}
+	}
+
+	if len(missing.Missing) > 0 {
+		return missing
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPreflight(t *testing.T) {
+	configMapProfile := &Profile{
+		ProfileName: "webapp",
+		Namespace:   "payments",
+		ProfileSource: ProfileSourceConfig{
+			Type:      SourceTypeConfigMap,
+			ConfigMap: &ConfigMapSourceConfig{Name: "debug-profiles"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		profile *Profile
+		pod     string
+		denied  map[string]bool // denied "verb resource/subresource"
+		want    string
+	}{
+		{
+			name:    "all allowed",
+			profile: configMapProfile,
+		},
+		{
+			name:    "ephemeral containers and configmap denied",
+			profile: configMapProfile,
+			pod:     "webapp-0",
+			denied:  map[string]bool{"patch pods/ephemeralcontainers": true, "get configmaps/": true},
+			want: `missing permissions: cannot get configmaps debug-profiles in namespace "payments" (needed to read the profile spec); ` +
+				`cannot patch pods/ephemeralcontainers webapp-0 in namespace "payments" (needed to add the debug container)`,
+		},
+		{
+			name:    "list pods is only needed for matchLabels",
+			profile: &Profile{ProfileName: "general", Namespace: "default"},
+			denied:  map[string]bool{"list pods/": true},
+			want:    `missing permissions: cannot list pods in namespace "default" (needed to find the target pod by the workload or the selector of the profile)`,
+		},
+		{
+			name:    "list pods is not needed with a pod",
+			profile: &Profile{ProfileName: "general", Namespace: "default"},
+			pod:     "webapp-0",
+			denied:  map[string]bool{"list pods/": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				attrs := review.Spec.ResourceAttributes
+				review.Status.Allowed = !tt.denied[attrs.Verb+" "+attrs.Resource+"/"+attrs.Subresource]
+				return true, review, nil
+			})
+
+			err := Preflight(context.Background(), client.AuthorizationV1(), tt.profile, tt.profile.Namespace, tt.pod)
+
+			var got string
+			if err != nil {
+				var missing *MissingAccessError
+				if !errors.As(err, &missing) {
+					t.Fatalf("Preflight() error = %v, want a *MissingAccessError", err)
+				}
+				got = err.Error()
+			}
+
+			if got != tt.want {
+				t.Errorf("Preflight() error =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AdHocProfileName is the name of the profile run with a spec or a source of the command line
+const AdHocProfileName = "ad-hoc"
+
+// ParseSourceRef parses a profile source of the command line:
+//   - git+URL#PATH[@REF] with a branch or tag as REF, e.g. git+https://github.com/org/profiles#netadmin.json@v1.0.0
+//   - builtin:NAME, e.g. builtin:netadmin
+//   - configmap:NAME, the configmap is read from the namespace of the profile
+//   - file:PATH or a plain path
+func ParseSourceRef(ref string) (ProfileSourceConfig, error) {
+	if rest, ok := strings.CutPrefix(ref, "git+"); ok {
+		url, pathRef, found := strings.Cut(rest, "#")
+		if !found || url == "" || pathRef == "" {
+			return ProfileSourceConfig{}, fmt.Errorf("invalid git source %q, must be git+URL#PATH[@REF]", ref)
+		}
+
+		path, gitRef := pathRef, ""
+		if i := strings.LastIndex(pathRef, "@"); i >= 0 {
+			path, gitRef = pathRef[:i], pathRef[i+1:]
+		}
+		if path == "" {
+			return ProfileSourceConfig{}, fmt.Errorf("missing path in git source %q", ref)
+		}
+
+		return ProfileSourceConfig{
+			Type: SourceTypeGit,
+			Git:  &GitSourceConfig{URL: url, Ref: gitRef, Path: path},
+		}, nil
+	}
+
+	sourceType, value, found := strings.Cut(ref, ":")
+	switch {
+	case found && sourceType == SourceTypeBuiltIn:
+		return ProfileSourceConfig{Type: SourceTypeBuiltIn, Name: value}, nil
+	case found && sourceType == SourceTypeConfigMap:
+		return ProfileSourceConfig{Type: SourceTypeConfigMap, ConfigMap: &ConfigMapSourceConfig{Name: value}}, nil
+	case found && sourceType == SourceTypeFile:
+		return ProfileSourceConfig{Type: SourceTypeFile, Path: value}, nil
+	case ref == "":
+		return ProfileSourceConfig{}, fmt.Errorf("empty profile source")
+	default:
+		return ProfileSourceConfig{Type: SourceTypeFile, Path: ref}, nil
+	}
+}
+
+// AddAdHocProfile adds a profile which isn't part of the configuration file, e.g. to
+// test a new profile spec. The profile is validated like configured profiles by ValidateProfile.
+func AddAdHocProfile(p Profile) error {
+	if _, err := GetProfileIdx(p.ProfileName); err == nil {
+		return fmt.Errorf("profile %q already exists in the configuration", p.ProfileName)
+	}
+
+	Config.Profiles = append(Config.Profiles, p)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseSourceRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		want    ProfileSourceConfig
+		wantErr bool
+	}{
+		{
+			name: "git with ref",
+			ref:  "git+https://github.com/org/profiles#debug/netadmin.json@v1.0.0",
+			want: ProfileSourceConfig{Type: SourceTypeGit, Git: &GitSourceConfig{
+				URL: "https://github.com/org/profiles", Ref: "v1.0.0", Path: "debug/netadmin.json",
+			}},
+		},
+		{
+			name: "git without ref",
+			ref:  "git+ssh://git@github.com/org/profiles.git#netadmin.json",
+			want: ProfileSourceConfig{Type: SourceTypeGit, Git: &GitSourceConfig{
+				URL: "ssh://git@github.com/org/profiles.git", Path: "netadmin.json",
+			}},
+		},
+		{name: "git without path", ref: "git+https://github.com/org/profiles", wantErr: true},
+		{name: "git with empty path", ref: "git+https://github.com/org/profiles#@main", wantErr: true},
+		{
+			name: "builtin",
+			ref:  "builtin:netadmin",
+			want: ProfileSourceConfig{Type: SourceTypeBuiltIn, Name: "netadmin"},
+		},
+		{
+			name: "configmap",
+			ref:  "configmap:debug-profiles",
+			want: ProfileSourceConfig{Type: SourceTypeConfigMap, ConfigMap: &ConfigMapSourceConfig{Name: "debug-profiles"}},
+		},
+		{
+			name: "file",
+			ref:  "file:./my.json",
+			want: ProfileSourceConfig{Type: SourceTypeFile, Path: "./my.json"},
+		},
+		{
+			name: "plain path",
+			ref:  "/tmp/my.json",
+			want: ProfileSourceConfig{Type: SourceTypeFile, Path: "/tmp/my.json"},
+		},
+		{name: "empty", ref: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSourceRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSourceRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSourceRef() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddAdHocProfile(t *testing.T) {
+	Config = CustomDebugProfile{Profiles: []Profile{{ProfileName: "webapp"}}}
+	t.Cleanup(func() { Config = CustomDebugProfile{} })
+
+	adHoc := Profile{
+		ProfileName:   AdHocProfileName,
+		ProfileSource: ProfileSourceConfig{Type: SourceTypeBuiltIn, Name: "netadmin"},
+		Image:         "busybox",
+	}
+
+	if err := AddAdHocProfile(adHoc); err != nil {
+		t.Fatalf("AddAdHocProfile() error = %v", err)
+	}
+
+	if err := ValidateProfile(context.Background(), AdHocProfileName); err != nil {
+		t.Errorf("ValidateProfile() error = %v", err)
+	}
+
+	if err := AddAdHocProfile(adHoc); err == nil {
+		t.Error("AddAdHocProfile() of an existing profile error = nil")
+	}
+}
@@ -0,0 +1,356 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// CheckStatus is the outcome of a single cluster check
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// severity orders the check status from pass to fail
+func (s CheckStatus) severity() int {
+	switch s {
+	case CheckFail:
+		return 2
+	case CheckWarn:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ClusterCheck is the result of a single check of a profile against the cluster
+type ClusterCheck struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+}
+
+// ClusterReport contains the results of all checks of a profile against its target pod
+type ClusterReport struct {
+	Profile   string
+	Namespace string
+	Pod       string
+	Checks    []ClusterCheck
+	// TargetPod is the resolved target pod, nil if no pod was found
+	TargetPod *corev1.Pod
+}
+
+// Status returns the worst status of all checks
+func (r *ClusterReport) Status() CheckStatus {
+	status := CheckPass
+	for _, c := range r.Checks {
+		if c.Status.severity() > status.severity() {
+			status = c.Status
+		}
+	}
+	return status
+}
+
+func (r *ClusterReport) add(name string, status CheckStatus, format string, args ...any) {
+	r.Checks = append(r.Checks, ClusterCheck{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+const (
+	// pod security admission labels of a namespace
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	podSecurityWarnLabel    = "pod-security.kubernetes.io/warn"
+
+	podSecurityPrivileged = "privileged"
+	podSecurityBaseline   = "baseline"
+	podSecurityRestricted = "restricted"
+)
+
+// ValidateProfileInCluster resolves the target pod of the profile in namespace (the workload
+// of the profile must be resolved with SetWorkloadSelector before) and checks
+// whether a debug container created with the profile would work there: the target container
+// and the mounted volumes must exist, the image must be pullable and the security settings
+// must be admitted by Pod Security Admission of the namespace.
+func ValidateProfileInCluster(ctx context.Context, client corev1client.CoreV1Interface, p Profile, namespace string) ClusterReport {
+	report := ClusterReport{Profile: p.ProfileName, Namespace: namespace}
+
+	if p.ProfileSource.Type == SourceTypeConfigMap {
+		if err := InitializeConfigMapSource(ctx, &p, client); err != nil {
+			report.add("spec", CheckFail, "%v", err)
+			return report
+		}
+	}
+
+	debugContainer, err := p.DebugContainer(ctx)
+	if err != nil {
+		report.add("spec", CheckFail, "%v", err)
+		return report
+	}
+
+	if !p.HasPodSelector() {
+		report.add("pod", CheckFail, "profile has no workload or selector, the target pod can't be resolved")
+		return report
+	}
+
+	podSelector, err := p.PodSelector()
+	if err != nil {
+		report.add("pod", CheckFail, "%v", err)
+		return report
+	}
+	selector := "label selector " + podSelector.String()
+	if p.FieldSelector != "" {
+		selector = fmt.Sprintf("label selector %q and field selector %q", podSelector, p.FieldSelector)
+	}
+
+	pods, err := client.Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: podSelector.String(), FieldSelector: p.FieldSelector})
+	if err != nil {
+		report.add("pod", CheckFail, "list pods: %v", err)
+		return report
+	}
+	if len(pods.Items) == 0 {
+		report.add("pod", CheckFail, "no pod found with %s", selector)
+		return report
+	}
+
+	// same pod as "kubectl dpm run" would pick
+	pod := &pods.Items[len(pods.Items)-1]
+	report.Pod = pod.Name
+	report.TargetPod = pod
+	report.add("pod", CheckPass, "%d pod(s) match %s, checking %s", len(pods.Items), selector, pod.Name)
+
+	checkTargetContainer(&report, &p, pod)
+	checkInheritEnvFrom(&report, &p, pod)
+	checkVolumeMounts(&report, debugContainer, pod)
+
+	events, err := client.Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.name=" + pod.Name,
+	})
+	if err != nil {
+		report.add("image", CheckWarn, "list events of pod %s: %v", pod.Name, err)
+	} else {
+		checkImage(&report, p.Image, pod, events.Items)
+	}
+
+	ns, err := client.Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		report.add("podSecurity", CheckWarn, "get namespace %q: %v", namespace, err)
+	} else {
+		checkPodSecurity(&report, debugContainer, pod, ns.Labels)
+	}
+
+	return report
+}
+
+func checkTargetContainer(report *ClusterReport, p *Profile, pod *corev1.Pod) {
+	if p.TargetContainer == "" {
+		report.add("targetContainer", CheckPass, "no target container configured")
+		return
+	}
+
+	for _, c := range pod.Spec.Containers {
+		if c.Name == p.TargetContainer {
+			report.add("targetContainer", CheckPass, "container %q exists", p.TargetContainer)
+			return
+		}
+	}
+
+	report.add("targetContainer", CheckFail, "container %q not found in pod %s (containers: %s)",
+		p.TargetContainer, pod.Name, containerNames(pod))
+}
+
+func checkInheritEnvFrom(report *ClusterReport, p *Profile, pod *corev1.Pod) {
+	if p.InheritEnvFrom == "" {
+		return
+	}
+
+	if err := p.InheritEnv(pod); err != nil {
+		report.add("inheritEnvFrom", CheckFail, "container %q not found in pod %s (containers: %s)",
+			p.InheritEnvFrom, pod.Name, containerNames(pod))
+		return
+	}
+
+	report.add("inheritEnvFrom", CheckPass, "inherits %d env and %d envFrom entries of container %q",
+		len(p.inheritedEnv.Env), len(p.inheritedEnv.EnvFrom), p.InheritEnvFrom)
+}
+
+// containerNames returns the comma separated names of the containers of the pod
+func containerNames(pod *corev1.Pod) string {
+	names := make([]string, 0, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func checkVolumeMounts(report *ClusterReport, debugContainer *corev1.Container, pod *corev1.Pod) {
+	var missing []string
+
+	for _, m := range debugContainer.VolumeMounts {
+		if !slices.ContainsFunc(pod.Spec.Volumes, func(v corev1.Volume) bool { return v.Name == m.Name }) {
+			missing = append(missing, m.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		report.add("volumeMounts", CheckFail, "volume(s) %s not found in pod %s", strings.Join(missing, ", "), pod.Name)
+		return
+	}
+
+	report.add("volumeMounts", CheckPass, "all %d volumeMount(s) reference existing volumes", len(debugContainer.VolumeMounts))
+}
+
+// pullFailureReasons are the container waiting reasons and event reasons of failed image pulls
+var pullFailureReasons = []string{"ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull", "Failed"}
+
+func checkImage(report *ClusterReport, image string, pod *corev1.Pod, events []corev1.Event) {
+	if image == "" {
+		report.add("image", CheckWarn, "profile has no image, kubectl debug uses its default image")
+		return
+	}
+
+	// debug containers of earlier sessions with the same image
+	for _, s := range pod.Status.EphemeralContainerStatuses {
+		if s.Image == image && s.State.Waiting != nil && slices.Contains(pullFailureReasons, s.State.Waiting.Reason) {
+			report.add("image", CheckFail, "image %q can't be pulled: %s", image, s.State.Waiting.Message)
+			return
+		}
+	}
+
+	quoted := fmt.Sprintf("%q", image)
+	pulled := false
+
+	for _, e := range events {
+		if e.InvolvedObject.Name != pod.Name || !strings.Contains(e.Message, quoted) {
+			continue
+		}
+
+		switch {
+		case slices.Contains(pullFailureReasons, e.Reason):
+			report.add("image", CheckFail, "image %q can't be pulled: %s", image, e.Message)
+			return
+		case e.Reason == "Pulled":
+			pulled = true
+		}
+	}
+
+	if pulled {
+		report.add("image", CheckPass, "image %q was pulled for pod %s", image, pod.Name)
+		return
+	}
+
+	report.add("image", CheckWarn, "no pull of image %q recorded in the events of pod %s", image, pod.Name)
+}
+
+func checkPodSecurity(report *ClusterReport, debugContainer *corev1.Container, pod *corev1.Pod, namespaceLabels map[string]string) {
+	enforce := namespaceLabels[podSecurityEnforceLabel]
+	if enforce == "" {
+		enforce = podSecurityPrivileged
+	}
+
+	if violations := podSecurityViolations(enforce, debugContainer, pod); len(violations) > 0 {
+		report.add("podSecurity", CheckFail, "rejected by enforce level %q: %s", enforce, strings.Join(violations, "; "))
+		return
+	}
+
+	if warn := namespaceLabels[podSecurityWarnLabel]; warn != "" {
+		if violations := podSecurityViolations(warn, debugContainer, pod); len(violations) > 0 {
+			report.add("podSecurity", CheckWarn, "violates warn level %q: %s", warn, strings.Join(violations, "; "))
+			return
+		}
+	}
+
+	report.add("podSecurity", CheckPass, "admitted by enforce level %q", enforce)
+}
+
+// baselineCapabilities are the capabilities which may be added under the baseline level
+var baselineCapabilities = []corev1.Capability{
+	"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+	"NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+}
+
+// podSecurityViolations returns the container level checks of the Pod Security Standards
+// the debug container violates at the given level. Pod level settings are taken from pod.
+func podSecurityViolations(level string, debugContainer *corev1.Container, pod *corev1.Pod) []string {
+	if level != podSecurityBaseline && level != podSecurityRestricted {
+		return nil
+	}
+
+	sc := debugContainer.SecurityContext
+	if sc == nil {
+		sc = &corev1.SecurityContext{}
+	}
+	podSC := pod.Spec.SecurityContext
+	if podSC == nil {
+		podSC = &corev1.PodSecurityContext{}
+	}
+
+	var violations []string
+
+	if sc.Privileged != nil && *sc.Privileged {
+		violations = append(violations, "privileged")
+	}
+
+	var added []corev1.Capability
+	if sc.Capabilities != nil {
+		added = sc.Capabilities.Add
+	}
+
+	allowed := baselineCapabilities
+	if level == podSecurityRestricted {
+		allowed = []corev1.Capability{"NET_BIND_SERVICE"}
+	}
+
+	for _, c := range added {
+		if !slices.Contains(allowed, corev1.Capability(strings.TrimPrefix(string(c), "CAP_"))) {
+			violations = append(violations, fmt.Sprintf("capability %s", c))
+		}
+	}
+
+	seccomp := sc.SeccompProfile
+	if seccomp == nil {
+		seccomp = podSC.SeccompProfile
+	}
+	if seccomp != nil && seccomp.Type == corev1.SeccompProfileTypeUnconfined {
+		violations = append(violations, "seccompProfile Unconfined")
+	}
+
+	if level == podSecurityBaseline {
+		return violations
+	}
+
+	if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		violations = append(violations, "allowPrivilegeEscalation != false")
+	}
+
+	runAsNonRoot := sc.RunAsNonRoot
+	if runAsNonRoot == nil {
+		runAsNonRoot = podSC.RunAsNonRoot
+	}
+	if runAsNonRoot == nil || !*runAsNonRoot {
+		violations = append(violations, "runAsNonRoot != true")
+	}
+
+	if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+		violations = append(violations, "runAsUser=0")
+	}
+
+	if sc.Capabilities == nil || !slices.Contains(sc.Capabilities.Drop, "ALL") {
+		violations = append(violations, "capabilities not dropping ALL")
+	}
+
+	if seccomp == nil {
+		violations = append(violations, "seccompProfile not set")
+	}
+
+	return violations
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateProfileInCluster(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), "webapp.json")
+	if err := os.WriteFile(specFile, []byte(testValidProfile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	namespace := func(enforce string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "default",
+			Labels: map[string]string{podSecurityEnforceLabel: enforce},
+		}}
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "webapp-x2x9q", Namespace: "default", Labels: map[string]string{"run": "webapp"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "webapp"}},
+			Volumes:    []corev1.Volume{{Name: "app-config"}},
+		},
+	}
+
+	pulled := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "pulled", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Name: "webapp-x2x9q"},
+		Reason:         "Pulled",
+		Message:        `Successfully pulled image "busybox:1.37"`,
+	}
+	pullFailed := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "failed", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Name: "webapp-x2x9q"},
+		Reason:         "Failed",
+		Message:        `Failed to pull image "busybox:1.37": not found`,
+	}
+
+	fileProfile := Profile{
+		ProfileName:     "webapp",
+		ProfileSource:   ProfileSourceConfig{Type: SourceTypeFile, Path: specFile},
+		Image:           "busybox:1.37",
+		MatchLabels:     map[string]string{"run": "webapp"},
+		TargetContainer: "webapp",
+	}
+
+	withSource := func(p Profile) Profile {
+		report := &Report{}
+		validateAndInstantiateProfileSource(context.Background(), &p, nil, report)
+		if report.Failed(false) {
+			t.Fatal(report.Findings)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name       string
+		profile    Profile
+		objects    []runtime.Object
+		wantStatus CheckStatus
+		wantChecks map[string]CheckStatus
+	}{
+		{
+			name:       "all checks pass",
+			profile:    withSource(fileProfile),
+			objects:    []runtime.Object{namespace(podSecurityPrivileged), pod, pulled},
+			wantStatus: CheckPass,
+			wantChecks: map[string]CheckStatus{
+				"pod": CheckPass, "targetContainer": CheckPass, "volumeMounts": CheckPass, "image": CheckPass, "podSecurity": CheckPass,
+			},
+		},
+		{
+			name:       "no matching pod",
+			profile:    withSource(fileProfile),
+			objects:    []runtime.Object{namespace(podSecurityPrivileged)},
+			wantStatus: CheckFail,
+			wantChecks: map[string]CheckStatus{"pod": CheckFail},
+		},
+		{
+			name: "missing target container and volume",
+			profile: func() Profile {
+				p := withSource(fileProfile)
+				p.TargetContainer = "app"
+				return p
+			}(),
+			objects: []runtime.Object{namespace(podSecurityPrivileged), &corev1.Pod{
+				ObjectMeta: pod.ObjectMeta,
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "webapp"}}},
+			}, pulled},
+			wantStatus: CheckFail,
+			wantChecks: map[string]CheckStatus{"targetContainer": CheckFail, "volumeMounts": CheckFail},
+		},
+		{
+			name: "missing inheritEnvFrom container",
+			profile: func() Profile {
+				p := withSource(fileProfile)
+				p.InheritEnvFrom = "sidecar"
+				return p
+			}(),
+			objects:    []runtime.Object{namespace(podSecurityPrivileged), pod, pulled},
+			wantStatus: CheckFail,
+			wantChecks: map[string]CheckStatus{"targetContainer": CheckPass, "inheritEnvFrom": CheckFail},
+		},
+		{
+			name:       "image pull failed",
+			profile:    withSource(fileProfile),
+			objects:    []runtime.Object{namespace(podSecurityPrivileged), pod, pullFailed},
+			wantStatus: CheckFail,
+			wantChecks: map[string]CheckStatus{"image": CheckFail},
+		},
+		{
+			name:       "image never pulled",
+			profile:    withSource(fileProfile),
+			objects:    []runtime.Object{namespace(podSecurityPrivileged), pod},
+			wantStatus: CheckWarn,
+			wantChecks: map[string]CheckStatus{"image": CheckWarn},
+		},
+		{
+			name: "netadmin rejected by baseline",
+			profile: withSource(Profile{
+				ProfileName:   "netadmin",
+				ProfileSource: ProfileSourceConfig{Type: SourceTypeBuiltIn, Name: "netadmin"},
+				Image:         "busybox:1.37",
+				MatchLabels:   map[string]string{"run": "webapp"},
+			}),
+			objects:    []runtime.Object{namespace(podSecurityBaseline), pod, pulled},
+			wantStatus: CheckFail,
+			wantChecks: map[string]CheckStatus{"podSecurity": CheckFail, "volumeMounts": CheckPass},
+		},
+		{
+			name: "restricted profile admitted by restricted",
+			profile: withSource(Profile{
+				ProfileName:   "restricted",
+				ProfileSource: ProfileSourceConfig{Type: SourceTypeBuiltIn, Name: "restricted"},
+				Image:         "busybox:1.37",
+				MatchLabels:   map[string]string{"run": "webapp"},
+			}),
+			objects:    []runtime.Object{namespace(podSecurityRestricted), pod, pulled},
+			wantStatus: CheckPass,
+			wantChecks: map[string]CheckStatus{"podSecurity": CheckPass},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.objects...)
+
+			report := ValidateProfileInCluster(context.Background(), client.CoreV1(), tt.profile, "default")
+
+			if got := report.Status(); got != tt.wantStatus {
+				t.Errorf("ValidateProfileInCluster() status = %q, want %q (checks: %+v)", got, tt.wantStatus, report.Checks)
+			}
+
+			got := map[string]CheckStatus{}
+			for _, c := range report.Checks {
+				got[c.Name] = c.Status
+			}
+			for name, want := range tt.wantChecks {
+				if got[name] != want {
+					t.Errorf("check %q = %q, want %q (checks: %+v)", name, got[name], want, report.Checks)
+				}
+			}
+		})
+	}
+}
+
+func TestPodSecurityViolations(t *testing.T) {
+	pod := &corev1.Pod{}
+
+	tests := []struct {
+		name      string
+		level     string
+		sc        *corev1.SecurityContext
+		wantCount int
+	}{
+		{name: "privileged level allows everything", level: podSecurityPrivileged, sc: &corev1.SecurityContext{Privileged: ptr.To(true)}},
+		{name: "baseline allows empty security context", level: podSecurityBaseline},
+		{name: "baseline rejects privileged", level: podSecurityBaseline, sc: &corev1.SecurityContext{Privileged: ptr.To(true)}, wantCount: 1},
+		{
+			name:      "baseline rejects CAP_SYS_ADMIN",
+			level:     podSecurityBaseline,
+			sc:        &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"CAP_SYS_ADMIN", "CHOWN"}}},
+			wantCount: 1,
+		},
+		{name: "restricted rejects empty security context", level: podSecurityRestricted, wantCount: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := podSecurityViolations(tt.level, &corev1.Container{SecurityContext: tt.sc}, pod)
+			if len(got) != tt.wantCount {
+				t.Errorf("podSecurityViolations() = %v, want %d violations", got, tt.wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ArtifactsConfig configures where collected files are stored
+type ArtifactsConfig struct {
+	// Dir of the collected files, environment variables get expanded (default: ~/.kube-dpm/artifacts)
+	Dir string `koanf:"dir" yaml:"dir"`
+}
+
+// ArtifactsDir returns the configured directory of the collected files
+func ArtifactsDir() string {
+	if Config.Artifacts.Dir != "" {
+		return os.ExpandEnv(Config.Artifacts.Dir)
+	}
+	return filepath.Join(os.Getenv("HOME"), ".kube-dpm", "artifacts")
+}
+
+// SessionProfile returns the profile of a debug session, the profile name of the session
+// is either the name of the profile or its sanitized name part of the container name
+func SessionProfile(s *Session) (*Profile, error) {
+	if s.Profile == "" {
+		return nil, fmt.Errorf("ephemeral container %s wasn't created by dpm", s.Container)
+	}
+
+	for i := range Config.Profiles {
+		p := &Config.Profiles[i]
+		if p.ProfileName == s.Profile || sessionName(p.ProfileName) == s.Profile {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("profile %q of debug session %s not found", s.Profile, s.Container)
+}
+
+func validateCollect(p *Profile, report *Report) {
+	for i, c := range p.Collect {
+		field := fmt.Sprintf("collect[%d]", i)
+
+		switch {
+		case !path.IsAbs(c):
+			report.Add(SeverityError, p.ProfileName, field, "path %q must be absolute", c)
+		case path.Clean(c) == "/":
+			report.Add(SeverityError, p.ProfileName, field, "collecting the root filesystem isn't supported")
+		case strings.ContainsAny(c, "*?["):
+			report.Add(SeverityWarning, p.ProfileName, field, "path %q contains a pattern, patterns aren't expanded", c)
+		}
+	}
+}
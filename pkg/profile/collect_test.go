@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"testing"
+)
+
+func TestSessionProfile(t *testing.T) {
+	oldConfig := Config
+	t.Cleanup(func() { Config = oldConfig })
+
+	Config.Profiles = []Profile{{ProfileName: "WebApp_Debug"}, {ProfileName: "netadmin"}}
+
+	tests := []struct {
+		name    string
+		session Session
+		want    string
+		wantErr bool
+	}{
+		{name: "annotated profile name", session: Session{Container: "dpm-webapp-debug-x7k2p", Profile: "WebApp_Debug"}, want: "WebApp_Debug"},
+		{name: "container name", session: Session{Container: "dpm-webapp-debug-x7k2p", Profile: "webapp-debug"}, want: "WebApp_Debug"},
+		{name: "unknown profile", session: Session{Container: "dpm-sysadmin-x7k2p", Profile: "sysadmin"}, wantErr: true},
+		{name: "not created by dpm", session: Session{Container: "debugger-abcde"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := SessionProfile(&tt.session)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SessionProfile() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err == nil && p.ProfileName != tt.want {
+				t.Errorf("SessionProfile() = %s, want %s", p.ProfileName, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCollect(t *testing.T) {
+	report := &Report{}
+	validateCollect(&Profile{ProfileName: "webapp", Collect: []string{"/tmp/capture.pcap", "tmp/heap.hprof", "/", "/tmp/*.pcap"}}, report)
+
+	want := []struct {
+		field    string
+		severity Severity
+	}{
+		{"collect[1]", SeverityError},
+		{"collect[2]", SeverityError},
+		{"collect[3]", SeverityWarning},
+	}
+
+	if len(report.Findings) != len(want) {
+		t.Fatalf("validateCollect() findings = %+v", report.Findings)
+	}
+	for i, w := range want {
+		if f := report.Findings[i]; f.Field != w.field || f.Severity != w.severity {
+			t.Errorf("finding %d = %s %s, want %s %s", i, f.Field, f.Severity, w.field, w.severity)
+		}
+	}
+}
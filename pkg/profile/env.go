@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// EnvFilter selects the environment inherited from the target container. Variables of env
+// are matched by their name, the sources of envFrom as configmap/NAME or secret/NAME.
+type EnvFilter struct {
+	// Include are shell patterns of the inherited entries, all entries are inherited if empty
+	Include []string `koanf:"include" yaml:"include" json:"include,omitempty"`
+	// Exclude are shell patterns of entries which aren't inherited
+	Exclude []string `koanf:"exclude" yaml:"exclude" json:"exclude,omitempty"`
+}
+
+// IsSet reports whether any filter is configured
+func (f EnvFilter) IsSet() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0
+}
+
+// matches reports whether the entry is included and not excluded
+func (f EnvFilter) matches(name string) bool {
+	match := func(pattern string) bool {
+		ok, err := path.Match(pattern, name)
+		return err == nil && ok
+	}
+
+	if len(f.Include) > 0 && !slices.ContainsFunc(f.Include, match) {
+		return false
+	}
+
+	return !slices.ContainsFunc(f.Exclude, match)
+}
+
+// envFromName returns configmap/NAME or secret/NAME of an envFrom source
+func envFromName(s corev1.EnvFromSource) string {
+	switch {
+	case s.ConfigMapRef != nil:
+		return "configmap/" + s.ConfigMapRef.Name
+	case s.SecretRef != nil:
+		return "secret/" + s.SecretRef.Name
+	default:
+		return ""
+	}
+}
+
+// inheritedEnv is the environment inherited from the target container at run time
+type inheritedEnv struct {
+	Env     []corev1.EnvVar
+	EnvFrom []corev1.EnvFromSource
+}
+
+func (e inheritedEnv) isSet() bool {
+	return len(e.Env) > 0 || len(e.EnvFrom) > 0
+}
+
+// apply adds the inherited environment to the JSON spec. Variables of the spec take
+// precedence over inherited variables with the same name.
+func (e inheritedEnv) apply(spec []byte) ([]byte, error) {
+	var container struct {
+		Env     []corev1.EnvVar        `json:"env"`
+		EnvFrom []corev1.EnvFromSource `json:"envFrom"`
+	}
+	fields := map[string]any{}
+
+	if len(spec) > 0 {
+		if err := json.Unmarshal(spec, &container); err != nil {
+			return nil, fmt.Errorf("parse profile spec: %w", err)
+		}
+		if err := json.Unmarshal(spec, &fields); err != nil {
+			return nil, fmt.Errorf("parse profile spec: %w", err)
+		}
+	}
+
+	var env []corev1.EnvVar
+	for _, v := range e.Env {
+		if !slices.ContainsFunc(container.Env, func(s corev1.EnvVar) bool { return s.Name == v.Name }) {
+			env = append(env, v)
+		}
+	}
+
+	if env = append(env, container.Env...); len(env) > 0 {
+		fields["env"] = env
+	}
+	if envFrom := append(slices.Clone(e.EnvFrom), container.EnvFrom...); len(envFrom) > 0 {
+		fields["envFrom"] = envFrom
+	}
+
+	return json.Marshal(fields)
+}
+
+// InheritEnv copies the env and envFrom of the container inheritEnvFrom of the target pod,
+// filtered by inheritEnvFilter, into the spec of the profile. Only the references to
+// ConfigMaps and Secrets are copied, their values aren't read.
+func (p *Profile) InheritEnv(pod *corev1.Pod) error {
+	if p.InheritEnvFrom == "" {
+		return nil
+	}
+
+	if pod == nil {
+		return fmt.Errorf("inheritEnvFrom of profile %q needs the target pod", p.ProfileName)
+	}
+
+	idx := slices.IndexFunc(pod.Spec.Containers, func(c corev1.Container) bool { return c.Name == p.InheritEnvFrom })
+	if idx == -1 {
+		return fmt.Errorf("container %q of inheritEnvFrom not found in pod %s", p.InheritEnvFrom, pod.Name)
+	}
+	source := pod.Spec.Containers[idx]
+
+	var inherited inheritedEnv
+	for _, v := range source.Env {
+		if !p.InheritEnvFilter.matches(v.Name) {
+			continue
+		}
+
+		// resource fields without container refer to the debug container, which has no resources
+		if v.ValueFrom != nil && v.ValueFrom.ResourceFieldRef != nil && v.ValueFrom.ResourceFieldRef.ContainerName == "" {
+			v = *v.DeepCopy()
+			v.ValueFrom.ResourceFieldRef.ContainerName = source.Name
+		}
+
+		inherited.Env = append(inherited.Env, v)
+	}
+
+	for _, s := range source.EnvFrom {
+		if p.InheritEnvFilter.matches(envFromName(s)) {
+			inherited.EnvFrom = append(inherited.EnvFrom, s)
+		}
+	}
+
+	p.inheritedEnv = inherited
+
+	return nil
+}
+
+func validateInheritEnv(p *Profile, report *Report) {
+	if p.InheritEnvFrom == "" && p.InheritEnvFilter.IsSet() {
+		report.Add(SeverityError, p.ProfileName, "inheritEnvFilter", "inheritEnvFilter needs inheritEnvFrom")
+	}
+
+	for _, pattern := range append(slices.Clone(p.InheritEnvFilter.Include), p.InheritEnvFilter.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			report.Add(SeverityError, p.ProfileName, "inheritEnvFilter", "invalid pattern %q: %v", pattern, err)
+		}
+	}
+}
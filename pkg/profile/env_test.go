@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInheritEnv(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "webapp-x2x9q"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "app",
+			Env: []corev1.EnvVar{
+				{Name: "DB_HOST", Value: "postgres"},
+				{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"},
+				}},
+				{Name: "MEMORY_LIMIT", ValueFrom: &corev1.EnvVarSource{
+					ResourceFieldRef: &corev1.ResourceFieldSelector{Resource: "limits.memory"},
+				}},
+				{Name: "LOG_LEVEL", Value: "info"},
+			},
+			EnvFrom: []corev1.EnvFromSource{
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"}}},
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app-secrets"}}},
+			},
+		}}},
+	}
+
+	tests := []struct {
+		name        string
+		container   string
+		filter      EnvFilter
+		pod         *corev1.Pod
+		wantEnv     []string
+		wantEnvFrom []string
+		wantErr     bool
+	}{
+		{name: "not configured", pod: pod},
+		{
+			name:        "everything",
+			container:   "app",
+			pod:         pod,
+			wantEnv:     []string{"DB_HOST", "DB_PASSWORD", "MEMORY_LIMIT", "LOG_LEVEL"},
+			wantEnvFrom: []string{"configmap/app-config", "secret/app-secrets"},
+		},
+		{
+			name:        "include and exclude",
+			container:   "app",
+			filter:      EnvFilter{Include: []string{"DB_*", "configmap/*"}, Exclude: []string{"*_PASSWORD"}},
+			pod:         pod,
+			wantEnv:     []string{"DB_HOST"},
+			wantEnvFrom: []string{"configmap/app-config"},
+		},
+		{
+			name:        "exclude secrets",
+			container:   "app",
+			filter:      EnvFilter{Exclude: []string{"secret/*", "DB_PASSWORD"}},
+			pod:         pod,
+			wantEnv:     []string{"DB_HOST", "MEMORY_LIMIT", "LOG_LEVEL"},
+			wantEnvFrom: []string{"configmap/app-config"},
+		},
+		{name: "unknown container", container: "sidecar", pod: pod, wantErr: true},
+		{name: "missing pod", container: "app", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Profile{ProfileName: "webapp", InheritEnvFrom: tt.container, InheritEnvFilter: tt.filter}
+
+			err := p.InheritEnv(tt.pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InheritEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var env, envFrom []string
+			for _, v := range p.inheritedEnv.Env {
+				env = append(env, v.Name)
+			}
+			for _, s := range p.inheritedEnv.EnvFrom {
+				envFrom = append(envFrom, envFromName(s))
+			}
+
+			if !slices.Equal(env, tt.wantEnv) || !slices.Equal(envFrom, tt.wantEnvFrom) {
+				t.Errorf("InheritEnv() env = %v, envFrom = %v, want %v and %v", env, envFrom, tt.wantEnv, tt.wantEnvFrom)
+			}
+		})
+	}
+
+	// resource fields must keep referring to the container they are inherited from
+	p := Profile{InheritEnvFrom: "app", InheritEnvFilter: EnvFilter{Include: []string{"MEMORY_LIMIT"}}}
+	if err := p.InheritEnv(pod); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.inheritedEnv.Env[0].ValueFrom.ResourceFieldRef.ContainerName; got != "app" {
+		t.Errorf("ResourceFieldRef.ContainerName = %q, want app", got)
+	}
+	if pod.Spec.Containers[0].Env[2].ValueFrom.ResourceFieldRef.ContainerName != "" {
+		t.Error("InheritEnv() modified the pod")
+	}
+}
+
+func TestSpecWithInheritedEnv(t *testing.T) {
+	p := Profile{ProfileName: "net", Profile: "netadmin"}
+	p.inheritedEnv = inheritedEnv{
+		Env:     []corev1.EnvVar{{Name: "DB_HOST", Value: "postgres"}, {Name: "LEVEL", Value: "info"}},
+		EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}}}},
+	}
+	p.SetSpecOverrides(SpecOverrides{Env: []corev1.EnvVar{{Name: "LEVEL", Value: "debug"}}})
+
+	container, err := p.DebugContainer(context.Background())
+	if err != nil {
+		t.Fatalf("DebugContainer() error = %v", err)
+	}
+
+	want := []corev1.EnvVar{{Name: "DB_HOST", Value: "postgres"}, {Name: "LEVEL", Value: "debug"}}
+	if !slices.Equal(container.Env, want) {
+		t.Errorf("Env = %v, want %v", container.Env, want)
+	}
+	if len(container.EnvFrom) != 1 || container.EnvFrom[0].SecretRef == nil {
+		t.Errorf("EnvFrom = %v, want the secret db", container.EnvFrom)
+	}
+	if container.SecurityContext == nil {
+		t.Error("SecurityContext of the built-in profile got lost")
+	}
+}
+
+func TestInheritedEnvApply(t *testing.T) {
+	e := inheritedEnv{Env: []corev1.EnvVar{{Name: "DB_HOST", Value: "postgres"}, {Name: "LEVEL", Value: "info"}}}
+
+	got, err := e.apply([]byte(`{"env":[{"name":"LEVEL","value":"debug"}],"image":"busybox"}`))
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	if want := `{"env":[{"name":"DB_HOST","value":"postgres"},{"name":"LEVEL","value":"debug"}],"image":"busybox"}`; string(got) != want {
+		t.Errorf("apply() = %s, want %s", got, want)
+	}
+}
+
+func TestValidateInheritEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		wantErr bool
+	}{
+		{name: "not configured", profile: Profile{}},
+		{name: "container with filter", profile: Profile{InheritEnvFrom: "app", InheritEnvFilter: EnvFilter{Include: []string{"DB_*"}}}},
+		{name: "filter without container", profile: Profile{InheritEnvFilter: EnvFilter{Exclude: []string{"secret/*"}}}, wantErr: true},
+		{name: "invalid pattern", profile: Profile{InheritEnvFrom: "app", InheritEnvFilter: EnvFilter{Include: []string{"DB_["}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &Report{}
+			validateInheritEnv(&tt.profile, report)
+			if got := report.Failed(false); got != tt.wantErr {
+				t.Errorf("validateInheritEnv() findings = %v, wantErr %v", report.Findings, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"time"
+)
+
+// SessionLimits limit the duration of debug sessions
+type SessionLimits struct {
+	// MaxDuration ends the attach to the debug container after the duration
+	MaxDuration time.Duration `koanf:"maxDuration" yaml:"maxDuration"`
+	// IdleTimeout ends the attach to the debug container after the duration without input
+	IdleTimeout time.Duration `koanf:"idleTimeout" yaml:"idleTimeout"`
+	// Kill kills the processes of the debug container when a limit is reached,
+	// otherwise the debug container keeps running and can be attached to again
+	Kill bool `koanf:"kill" yaml:"kill"`
+}
+
+// Active reports whether a limit is set
+func (l SessionLimits) Active() bool {
+	return l.MaxDuration > 0 || l.IdleTimeout > 0
+}
+
+// SessionLimits returns the effective limits of the profile, the stricter one of
+// the global and the profile limits applies
+func (p *Profile) SessionLimits() SessionLimits {
+	global := Config.Sessions.Limits
+
+	return SessionLimits{
+		MaxDuration: stricterLimit(global.MaxDuration, p.Limits.MaxDuration),
+		IdleTimeout: stricterLimit(global.IdleTimeout, p.Limits.IdleTimeout),
+		Kill:        global.Kill || p.Limits.Kill,
+	}
+}
+
+// stricterLimit returns the shorter limit, 0 means no limit
+func stricterLimit(a, b time.Duration) time.Duration {
+	switch {
+	case a <= 0:
+		return max(b, 0)
+	case b <= 0:
+		return a
+	default:
+		return min(a, b)
+	}
+}
+
+// WarnBefore returns how long before a limit is reached the user gets warned
+func WarnBefore(limit time.Duration) time.Duration {
+	return min(time.Minute, limit/10)
+}
+
+// validateLimits validates the limits at field, active is set if any effective limit applies
+func validateLimits(field, profileName string, l SessionLimits, active bool, report *Report) {
+	if l.MaxDuration < 0 {
+		report.Add(SeverityError, profileName, field+".maxDuration", "must not be negative")
+	}
+	if l.IdleTimeout < 0 {
+		report.Add(SeverityError, profileName, field+".idleTimeout", "must not be negative")
+	}
+	if l.Kill && !active {
+		report.Add(SeverityWarning, profileName, field+".kill", "has no effect without maxDuration or idleTimeout")
+	}
+}
+
+// FormatLimit formats a limit, it's empty without a limit
+func FormatLimit(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionLimits(t *testing.T) {
+	oldConfig := Config
+	t.Cleanup(func() { Config = oldConfig })
+
+	tests := []struct {
+		name    string
+		global  SessionLimits
+		profile SessionLimits
+		want    SessionLimits
+	}{
+		{name: "no limits"},
+		{
+			name:   "global limits",
+			global: SessionLimits{MaxDuration: 8 * time.Hour, IdleTimeout: 30 * time.Minute},
+			want:   SessionLimits{MaxDuration: 8 * time.Hour, IdleTimeout: 30 * time.Minute},
+		},
+		{
+			name:    "stricter profile limits",
+			global:  SessionLimits{MaxDuration: 8 * time.Hour, IdleTimeout: 30 * time.Minute},
+			profile: SessionLimits{MaxDuration: time.Hour, IdleTimeout: time.Hour, Kill: true},
+			want:    SessionLimits{MaxDuration: time.Hour, IdleTimeout: 30 * time.Minute, Kill: true},
+		},
+		{
+			name:    "profile limits only",
+			profile: SessionLimits{IdleTimeout: 15 * time.Minute},
+			want:    SessionLimits{IdleTimeout: 15 * time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config.Sessions.Limits = tt.global
+			p := &Profile{ProfileName: "webapp", Limits: tt.profile}
+
+			if got := p.SessionLimits(); got != tt.want {
+				t.Errorf("SessionLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLimits(t *testing.T) {
+	report := &Report{}
+	validateLimits("limits", "webapp", SessionLimits{MaxDuration: -time.Minute, Kill: true}, false, report)
+
+	want := []string{"limits.maxDuration", "limits.kill"}
+	if len(report.Findings) != len(want) {
+		t.Fatalf("validateLimits() findings = %+v", report.Findings)
+	}
+	for i, field := range want {
+		if report.Findings[i].Field != field {
+			t.Errorf("finding %d field = %s, want %s", i, report.Findings[i].Field, field)
+		}
+	}
+}
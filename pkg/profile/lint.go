@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SeverityOff disables a lint rule in the lint section of the config
+const SeverityOff Severity = "off"
+
+const (
+	LintDangerousCapabilities   = "dangerous-capabilities"
+	LintPrivileged              = "privileged"
+	LintRunAsRoot               = "run-as-root"
+	LintWritableSecretMount     = "writable-secret-mount"
+	LintLatestImageIfNotPresent = "latest-image-if-not-present"
+)
+
+// LintConfig configures the lint rules
+type LintConfig struct {
+	// Rules overrides the severity of a rule by its name, "off" disables the rule
+	Rules map[string]string `koanf:"rules" yaml:"rules"`
+}
+
+// lintInput is everything a lint rule can check
+type lintInput struct {
+	profile   *Profile
+	container *corev1.Container
+	// pod is the target pod, nil if it is unknown (e.g. "kubectl dpm validate" without --live)
+	pod *corev1.Pod
+}
+
+// lintViolation is a single violation of a lint rule
+type lintViolation struct {
+	field   string
+	message string
+}
+
+// LintRule is a security check of a profile
+type LintRule struct {
+	Name            string
+	DefaultSeverity Severity
+	Description     string
+	check           func(in *lintInput) []lintViolation
+}
+
+// dangerousCapabilities are the capabilities which (almost) grant root on the node
+var dangerousCapabilities = []string{
+	"ALL", "SYS_ADMIN", "SYS_MODULE", "SYS_RAWIO", "SYS_BOOT", "SYS_PTRACE",
+	"DAC_READ_SEARCH", "BPF", "NET_ADMIN", "SYS_TIME", "MAC_ADMIN", "MAC_OVERRIDE",
+}
+
+// LintRules are all built-in lint rules
+var LintRules = []LintRule{
+	{
+		Name:            LintDangerousCapabilities,
+		DefaultSeverity: SeverityError,
+		Description:     "the debug container adds capabilities like CAP_SYS_ADMIN",
+		check: func(in *lintInput) []lintViolation {
+			sc := in.container.SecurityContext
+			if sc == nil || sc.Capabilities == nil {
+				return nil
+			}
+
+			var violations []lintViolation
+			for _, c := range sc.Capabilities.Add {
+				if slices.Contains(dangerousCapabilities, strings.TrimPrefix(strings.ToUpper(string(c)), "CAP_")) {
+					violations = append(violations, lintViolation{
+						field:   "spec.securityContext.capabilities.add",
+						message: fmt.Sprintf("adds dangerous capability %s", c),
+					})
+				}
+			}
+			return violations
+		},
+	},
+	{
+		Name:            LintPrivileged,
+		DefaultSeverity: SeverityError,
+		Description:     "the debug container runs privileged",
+		check: func(in *lintInput) []lintViolation {
+			sc := in.container.SecurityContext
+			if sc == nil || sc.Privileged == nil || !*sc.Privileged {
+				return nil
+			}
+			return []lintViolation{{field: "spec.securityContext.privileged", message: "runs privileged"}}
+		},
+	},
+	{
+		Name:            LintRunAsRoot,
+		DefaultSeverity: SeverityWarning,
+		Description:     "the debug container runs as root (runAsUser 0)",
+		check: func(in *lintInput) []lintViolation {
+			sc := in.container.SecurityContext
+			if sc == nil || sc.RunAsUser == nil || *sc.RunAsUser != 0 {
+				return nil
+			}
+			return []lintViolation{{field: "spec.securityContext.runAsUser", message: "runs as root (runAsUser 0)"}}
+		},
+	},
+	{
+		Name:            LintWritableSecretMount,
+		DefaultSeverity: SeverityWarning,
+		Description:     "the debug container mounts a secret volume of the target pod writable",
+		check: func(in *lintInput) []lintViolation {
+			if in.pod == nil {
+				return nil
+			}
+
+			var violations []lintViolation
+			for i, m := range in.container.VolumeMounts {
+				if m.ReadOnly {
+					continue
+				}
+				idx := slices.IndexFunc(in.pod.Spec.Volumes, func(v corev1.Volume) bool { return v.Name == m.Name })
+				if idx == -1 || !isSecretVolume(&in.pod.Spec.Volumes[idx]) {
+					continue
+				}
+				violations = append(violations, lintViolation{
+					field:   fmt.Sprintf("spec.volumeMounts[%d].readOnly", i),
+					message: fmt.Sprintf("mounts secret volume %q writable at %s", m.Name, m.MountPath),
+				})
+			}
+			return violations
+		},
+	},
+	{
+		Name:            LintLatestImageIfNotPresent,
+		DefaultSeverity: SeverityWarning,
+		Description:     "the image uses the latest tag with imagePullPolicy IfNotPresent",
+		check: func(in *lintInput) []lintViolation {
+			if in.profile.Image == "" || in.profile.ImagePullPolicy != corev1.PullIfNotPresent || !isLatestImage(in.profile.Image) {
+				return nil
+			}
+			return []lintViolation{{
+				field:   "image",
+				message: fmt.Sprintf("image %q uses the latest tag with imagePullPolicy IfNotPresent, nodes may run outdated images", in.profile.Image),
+			}}
+		},
+	},
+}
+
+func isSecretVolume(v *corev1.Volume) bool {
+	if v.Secret != nil {
+		return true
+	}
+	if v.Projected != nil {
+		for _, s := range v.Projected.Sources {
+			if s.Secret != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isLatestImage reports whether the image has the latest tag or no tag (and no digest) at all
+func isLatestImage(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+
+	// the tag is after the last colon, unless the colon belongs to a registry port
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, found := strings.Cut(name, ":")
+
+	return !found || tag == "latest"
+}
+
+// lintSeverity returns the configured severity of a rule
+func lintSeverity(rule *LintRule) Severity {
+	if s, ok := Config.Lint.Rules[rule.Name]; ok && s != "" {
+		return Severity(s)
+	}
+	return rule.DefaultSeverity
+}
+
+// LintProfile checks the spec of a profile against all enabled lint rules. pod is the
+// target pod and may be nil, rules which need it are skipped then. Built-in profiles
+// aren't linted, using them is an explicit decision.
+// The profile source must be instantiated (see ValidateProfile).
+func LintProfile(ctx context.Context, p *Profile, pod *corev1.Pod) ([]Finding, error) {
+	if p.IsBuiltInProfile() {
+		return nil, nil
+	}
+
+	container, err := p.DebugContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	in := &lintInput{profile: p, container: container, pod: pod}
+
+	var findings []Finding
+
+	for i := range LintRules {
+		rule := &LintRules[i]
+
+		severity := lintSeverity(rule)
+		if severity == SeverityOff {
+			continue
+		}
+
+		for _, v := range rule.check(in) {
+			findings = append(findings, Finding{
+				Severity: severity,
+				Profile:  p.ProfileName,
+				Field:    v.field,
+				Message:  fmt.Sprintf("%s (%s)", v.message, rule.Name),
+			})
+		}
+	}
+
+	return findings, nil
+}
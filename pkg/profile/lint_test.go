@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestLintProfile(t *testing.T) {
+	secretPod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "tls"}}},
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		container corev1.Container
+		image     string
+		policy    corev1.PullPolicy
+		pod       *corev1.Pod
+		rules     map[string]string
+		want      []Severity
+		wantRules []string
+	}{
+		{
+			name:  "no findings",
+			image: "busybox:1.37",
+		},
+		{
+			name: "dangerous capabilities and privileged",
+			container: corev1.Container{SecurityContext: &corev1.SecurityContext{
+				Privileged:   ptr.To(true),
+				Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"CAP_SYS_ADMIN", "NET_BIND_SERVICE"}},
+			}},
+			want:      []Severity{SeverityError, SeverityError},
+			wantRules: []string{LintDangerousCapabilities, LintPrivileged},
+		},
+		{
+			name:      "root user",
+			container: corev1.Container{SecurityContext: &corev1.SecurityContext{RunAsUser: ptr.To(int64(0))}},
+			want:      []Severity{SeverityWarning},
+			wantRules: []string{LintRunAsRoot},
+		},
+		{
+			name: "writable secret mount with known pod",
+			container: corev1.Container{VolumeMounts: []corev1.VolumeMount{
+				{Name: "tls", MountPath: "/tls"},
+				{Name: "config", MountPath: "/config"},
+			}},
+			pod:       secretPod,
+			want:      []Severity{SeverityWarning},
+			wantRules: []string{LintWritableSecretMount},
+		},
+		{
+			name:      "writable secret mount without pod is skipped",
+			container: corev1.Container{VolumeMounts: []corev1.VolumeMount{{Name: "tls", MountPath: "/tls"}}},
+		},
+		{
+			name:      "latest image with IfNotPresent",
+			image:     "nicolaka/netshoot",
+			policy:    corev1.PullIfNotPresent,
+			want:      []Severity{SeverityWarning},
+			wantRules: []string{LintLatestImageIfNotPresent},
+		},
+		{
+			name:   "latest image with Always",
+			image:  "nicolaka/netshoot:latest",
+			policy: corev1.PullAlways,
+		},
+		{
+			name: "severities configured",
+			container: corev1.Container{SecurityContext: &corev1.SecurityContext{
+				Privileged: ptr.To(true),
+				RunAsUser:  ptr.To(int64(0)),
+			}},
+			rules:     map[string]string{LintPrivileged: "off", LintRunAsRoot: "error"},
+			want:      []Severity{SeverityError},
+			wantRules: []string{LintRunAsRoot},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config = CustomDebugProfile{Lint: LintConfig{Rules: tt.rules}}
+
+			spec, err := json.Marshal(tt.container)
+			if err != nil {
+				t.Fatal(err)
+			}
+			specFile := filepath.Join(t.TempDir(), "spec.json")
+			if err := os.WriteFile(specFile, spec, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			p := &Profile{
+				ProfileName:     "test",
+				Image:           tt.image,
+				ImagePullPolicy: tt.policy,
+			}
+			p.SetSource(NewFileProfileSource(specFile))
+
+			findings, err := LintProfile(context.Background(), p, tt.pod)
+			if err != nil {
+				t.Fatalf("LintProfile() error = %v", err)
+			}
+
+			var gotSeverities []Severity
+			var gotRules []string
+			for _, f := range findings {
+				gotSeverities = append(gotSeverities, f.Severity)
+				// the rule name is appended to the message
+				gotRules = append(gotRules, strings.TrimSuffix(f.Message[strings.LastIndex(f.Message, "(")+1:], ")"))
+			}
+
+			if !reflect.DeepEqual(gotSeverities, tt.want) || !reflect.DeepEqual(gotRules, tt.wantRules) {
+				t.Errorf("LintProfile() = %+v, want severities %v of rules %v", findings, tt.want, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestLintProfile_BuiltIn(t *testing.T) {
+	Config = CustomDebugProfile{}
+
+	p := &Profile{ProfileName: "sysadmin"}
+	p.SetBuiltInProfile(true)
+
+	findings, err := LintProfile(context.Background(), p, nil)
+	if err != nil || len(findings) != 0 {
+		t.Errorf("LintProfile() = %v, %v, want no findings for built-in profiles", findings, err)
+	}
+}
+
+func TestIsLatestImage(t *testing.T) {
+	tests := map[string]bool{
+		"busybox":                         true,
+		"busybox:latest":                  true,
+		"busybox:1.37":                    false,
+		"registry:5000/busybox":           true,
+		"registry:5000/busybox:1.37":      false,
+		"busybox@sha256:0123456789abcdef": false,
+	}
+
+	for image, want := range tests {
+		if got := isLatestImage(image); got != want {
+			t.Errorf("isLatestImage(%q) = %v, want %v", image, got, want)
+		}
+	}
+}
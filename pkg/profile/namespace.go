@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// NamespaceSelector selects the namespaces a profile searches for its target pods.
+// A namespace must match one of Names or Regex (if any is set) and the MatchLabels.
+type NamespaceSelector struct {
+	// Names are namespace names or shell patterns as understood by path.Match (e.g. "tenant-*")
+	Names []string `koanf:"names" yaml:"names" json:"names,omitempty"`
+	// Regex is a regular expression the namespace name must match
+	Regex string `koanf:"regex" yaml:"regex" json:"regex,omitempty"`
+	// MatchLabels select the namespaces by their labels
+	MatchLabels map[string]string `koanf:"matchLabels" yaml:"matchLabels" json:"matchLabels,omitempty"`
+}
+
+// IsSet reports whether any namespace selection is configured
+func (s NamespaceSelector) IsSet() bool {
+	return len(s.Names) > 0 || s.Regex != "" || len(s.MatchLabels) > 0
+}
+
+// literalNames returns the names if they are plain namespace names and nothing else
+// is set, then the namespaces don't have to be listed
+func (s NamespaceSelector) literalNames() ([]string, bool) {
+	if s.Regex != "" || len(s.MatchLabels) > 0 {
+		return nil, false
+	}
+
+	for _, name := range s.Names {
+		if strings.ContainsAny(name, `*?[\`) {
+			return nil, false
+		}
+	}
+
+	return s.Names, true
+}
+
+// matchesName reports whether the namespace name matches the names or the regex
+func (s NamespaceSelector) matchesName(name string, re *regexp.Regexp) bool {
+	if len(s.Names) == 0 && re == nil {
+		return true
+	}
+
+	if re != nil && re.MatchString(name) {
+		return true
+	}
+
+	for _, pattern := range s.Names {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String formats the selector, e.g. "tenant-a,tenant-*; regex ^tenant-; labels team=payments"
+func (s NamespaceSelector) String() string {
+	var parts []string
+
+	if len(s.Names) > 0 {
+		parts = append(parts, strings.Join(s.Names, ","))
+	}
+	if s.Regex != "" {
+		parts = append(parts, "regex "+s.Regex)
+	}
+	if len(s.MatchLabels) > 0 {
+		parts = append(parts, "labels "+metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: s.MatchLabels}))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// HasNamespace reports whether the profile sets the namespace of its target pods
+func (p *Profile) HasNamespace() bool {
+	return p.Namespace != "" || p.Namespaces.IsSet()
+}
+
+// FormatNamespace formats the namespace or the namespaces of the profile
+func (p *Profile) FormatNamespace() string {
+	if p.Namespaces.IsSet() {
+		return p.Namespaces.String()
+	}
+	return p.Namespace
+}
+
+// TargetNamespaces returns the sorted namespaces selected by the namespaces of the profile.
+// Namespaces are only listed if the selector isn't a list of plain names.
+func (p *Profile) TargetNamespaces(ctx context.Context, client corev1client.NamespacesGetter) ([]string, error) {
+	if names, ok := p.Namespaces.literalNames(); ok {
+		return slices.Sorted(slices.Values(names)), nil
+	}
+
+	var re *regexp.Regexp
+	if p.Namespaces.Regex != "" {
+		var err error
+		if re, err = regexp.Compile(p.Namespaces.Regex); err != nil {
+			return nil, fmt.Errorf("namespaces.regex of profile %q: %w", p.ProfileName, err)
+		}
+	}
+
+	var listOptions metav1.ListOptions
+	if len(p.Namespaces.MatchLabels) > 0 {
+		listOptions.LabelSelector = metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: p.Namespaces.MatchLabels})
+	}
+
+	namespaces, err := client.Namespaces().List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+
+	var names []string
+	for _, ns := range namespaces.Items {
+		if p.Namespaces.matchesName(ns.Name, re) {
+			names = append(names, ns.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no namespace matches %s of profile %q", p.Namespaces, p.ProfileName)
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+func validateNamespaces(p *Profile, report *Report) {
+	if !p.Namespaces.IsSet() {
+		return
+	}
+
+	if p.Namespace != "" {
+		report.Add(SeverityError, p.ProfileName, "namespaces", "namespace and namespaces are mutually exclusive")
+	}
+
+	for _, pattern := range p.Namespaces.Names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			report.Add(SeverityError, p.ProfileName, "namespaces.names", "invalid pattern %q: %v", pattern, err)
+		}
+	}
+
+	if p.Namespaces.Regex != "" {
+		if _, err := regexp.Compile(p.Namespaces.Regex); err != nil {
+			report.Add(SeverityError, p.ProfileName, "namespaces.regex", "invalid regular expression: %v", err)
+		}
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: p.Namespaces.MatchLabels}); err != nil {
+		report.Add(SeverityError, p.ProfileName, "namespaces.matchLabels", "invalid label selector: %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTargetNamespaces(t *testing.T) {
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	client := fake.NewSimpleClientset(
+		namespace("tenant-b", map[string]string{"team": "payments"}),
+		namespace("tenant-a", map[string]string{"team": "payments"}),
+		namespace("tenant-c", map[string]string{"team": "search"}),
+		namespace("kube-system", nil),
+	)
+
+	tests := []struct {
+		name       string
+		namespaces NamespaceSelector
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name:       "plain names aren't listed",
+			namespaces: NamespaceSelector{Names: []string{"tenant-z", "tenant-a"}},
+			want:       []string{"tenant-a", "tenant-z"},
+		},
+		{
+			name:       "pattern",
+			namespaces: NamespaceSelector{Names: []string{"tenant-*"}},
+			want:       []string{"tenant-a", "tenant-b", "tenant-c"},
+		},
+		{
+			name:       "regex",
+			namespaces: NamespaceSelector{Regex: "^tenant-[ab]$"},
+			want:       []string{"tenant-a", "tenant-b"},
+		},
+		{
+			name:       "labels",
+			namespaces: NamespaceSelector{MatchLabels: map[string]string{"team": "payments"}},
+			want:       []string{"tenant-a", "tenant-b"},
+		},
+		{
+			name:       "pattern and labels",
+			namespaces: NamespaceSelector{Names: []string{"tenant-[bc]"}, MatchLabels: map[string]string{"team": "payments"}},
+			want:       []string{"tenant-b"},
+		},
+		{
+			name:       "no match",
+			namespaces: NamespaceSelector{Regex: "^prod-"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Profile{ProfileName: "test", Namespaces: tt.namespaces}
+
+			got, err := p.TargetNamespaces(context.Background(), client.CoreV1())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TargetNamespaces() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TargetNamespaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatNamespace(t *testing.T) {
+	p := Profile{Namespaces: NamespaceSelector{
+		Names:       []string{"tenant-a", "tenant-*"},
+		Regex:       "^tenant-",
+		MatchLabels: map[string]string{"team": "payments"},
+	}}
+
+	if got, want := p.FormatNamespace(), "tenant-a,tenant-*; regex ^tenant-; labels team=payments"; got != want {
+		t.Errorf("FormatNamespace() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateNamespaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		wantErr bool
+	}{
+		{name: "namespace only", profile: Profile{Namespace: "default"}},
+		{name: "namespaces", profile: Profile{Namespaces: NamespaceSelector{Names: []string{"tenant-*"}, Regex: "^shared-"}}},
+		{
+			name:    "namespace and namespaces",
+			profile: Profile{Namespace: "default", Namespaces: NamespaceSelector{Names: []string{"tenant-a"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			profile: Profile{Namespaces: NamespaceSelector{Names: []string{"tenant-["}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid regex",
+			profile: Profile{Namespaces: NamespaceSelector{Regex: "tenant-("}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &Report{}
+			tt.profile.ProfileName = "test"
+			validateNamespaces(&tt.profile, report)
+
+			if got := report.Count(SeverityError) > 0; got != tt.wantErr {
+				t.Errorf("validateNamespaces() findings = %v, wantErr %v", report.Findings, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// specSetPrefix is the prefix of the paths of --set, e.g. spec.securityContext.privileged=true
+const specSetPrefix = "spec."
+
+// SpecOverrides change the spec of a profile for a single run
+type SpecOverrides struct {
+	// Env is added to the env of the debug container, replacing variables with the same name
+	Env []corev1.EnvVar
+	// Set are the assignments of --set, paths are relative to the spec
+	Set []SpecAssignment
+}
+
+// SpecAssignment sets the value at a dot separated path of the spec
+type SpecAssignment struct {
+	Path  []string
+	Value any
+}
+
+// IsSet reports whether the overrides change the spec
+func (o SpecOverrides) IsSet() bool {
+	return len(o.Env) > 0 || len(o.Set) > 0
+}
+
+// ParseEnv parses KEY=VALUE pairs
+func ParseEnv(values []string) ([]corev1.EnvVar, error) {
+	env := make([]corev1.EnvVar, 0, len(values))
+
+	for _, v := range values {
+		name, value, found := strings.Cut(v, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid env %q, must be KEY=VALUE", v)
+		}
+		env = append(env, corev1.EnvVar{Name: name, Value: value})
+	}
+
+	return env, nil
+}
+
+// ParseSet parses spec.path=value assignments. Values are decoded as JSON if possible
+// (e.g. true, 1000 or ["NET_ADMIN"]), otherwise they are strings.
+func ParseSet(values []string) ([]SpecAssignment, error) {
+	assignments := make([]SpecAssignment, 0, len(values))
+
+	for _, v := range values {
+		key, raw, found := strings.Cut(v, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid assignment %q, must be spec.path=value", v)
+		}
+
+		path, ok := strings.CutPrefix(key, specSetPrefix)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid path %q, must start with %q", key, specSetPrefix)
+		}
+
+		segments := strings.Split(path, ".")
+		if slices.Contains(segments, "") {
+			return nil, fmt.Errorf("invalid path %q", key)
+		}
+
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+
+		assignments = append(assignments, SpecAssignment{Path: segments, Value: value})
+	}
+
+	return assignments, nil
+}
+
+// Apply applies the overrides to the JSON spec, an empty spec is an empty container
+func (o SpecOverrides) Apply(spec []byte) ([]byte, error) {
+	container := map[string]any{}
+	if len(strings.TrimSpace(string(spec))) > 0 {
+		if err := json.Unmarshal(spec, &container); err != nil {
+			return nil, fmt.Errorf("parse profile spec: %w", err)
+		}
+	}
+
+	if len(o.Env) > 0 {
+		env, _ := container["env"].([]any)
+		for _, e := range o.Env {
+			env = setEnv(env, e)
+		}
+		container["env"] = env
+	}
+
+	for _, s := range o.Set {
+		if err := setPath(container, s.Path, s.Value); err != nil {
+			return nil, fmt.Errorf("set spec.%s: %w", strings.Join(s.Path, "."), err)
+		}
+	}
+
+	return json.Marshal(container)
+}
+
+// setEnv replaces the variable with the same name or appends it
+func setEnv(env []any, e corev1.EnvVar) []any {
+	value := map[string]any{"name": e.Name, "value": e.Value}
+
+	for i, existing := range env {
+		if m, ok := existing.(map[string]any); ok && m["name"] == e.Name {
+			env[i] = value
+			return env
+		}
+	}
+
+	return append(env, value)
+}
+
+// setPath sets the value in nested objects, missing objects are created. Numeric
+// segments index lists, the index after the last element appends to the list.
+func setPath(node map[string]any, path []string, value any) error {
+	key := path[0]
+	if len(path) == 1 {
+		node[key] = value
+		return nil
+	}
+
+	switch child := node[key].(type) {
+	case nil:
+		m := map[string]any{}
+		node[key] = m
+		return setPath(m, path[1:], value)
+	case map[string]any:
+		return setPath(child, path[1:], value)
+	case []any:
+		list, err := setListPath(child, path[1:], value)
+		if err != nil {
+			return err
+		}
+		node[key] = list
+		return nil
+	default:
+		return fmt.Errorf("%q isn't an object or a list", key)
+	}
+}
+
+func setListPath(list []any, path []string, value any) ([]any, error) {
+	i, err := strconv.Atoi(path[0])
+	if err != nil || i < 0 || i > len(list) {
+		return nil, fmt.Errorf("invalid index %q of a list with %d elements", path[0], len(list))
+	}
+
+	if len(path) == 1 {
+		if i == len(list) {
+			return append(list, value), nil
+		}
+		list[i] = value
+		return list, nil
+	}
+
+	if i == len(list) {
+		list = append(list, map[string]any{})
+	}
+
+	m, ok := list[i].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("element %d isn't an object", i)
+	}
+
+	return list, setPath(m, path[1:], value)
+}
+
+// SetSpecOverrides sets the overrides applied to the spec of the profile
+func (p *Profile) SetSpecOverrides(o SpecOverrides) {
+	p.specOverrides = o
+}
+
+// SpecOverrides returns the overrides applied to the spec of the profile
+func (p *Profile) SpecOverrides() SpecOverrides {
+	return p.specOverrides
+}
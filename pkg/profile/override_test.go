@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		wantErr bool
+	}{
+		{name: "nested path", values: []string{"spec.securityContext.privileged=true"}},
+		{name: "string value with =", values: []string{"spec.args.0=--opt=1"}},
+		{name: "missing value", values: []string{"spec.image"}, wantErr: true},
+		{name: "missing spec prefix", values: []string{"securityContext.privileged=true"}, wantErr: true},
+		{name: "empty segment", values: []string{"spec.securityContext..privileged=true"}, wantErr: true},
+		{name: "empty path", values: []string{"spec.=true"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSet(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpecOverridesApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		env     []string
+		set     []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no spec",
+			set:  []string{"spec.securityContext.privileged=true"},
+			want: `{"securityContext":{"privileged":true}}`,
+		},
+		{
+			name: "env replaces variables with the same name",
+			spec: `{"env":[{"name":"LEVEL","value":"info"},{"name":"MODE","value":"a"}]}`,
+			env:  []string{"LEVEL=debug", "EXTRA=1"},
+			want: `{"env":[{"name":"LEVEL","value":"debug"},{"name":"MODE","value":"a"},{"name":"EXTRA","value":"1"}]}`,
+		},
+		{
+			name: "values are decoded as JSON",
+			spec: `{"securityContext":{"runAsUser":0}}`,
+			set:  []string{"spec.securityContext.runAsUser=1000", `spec.securityContext.capabilities.add=["NET_ADMIN"]`, "spec.workingDir=/tmp"},
+			want: `{"securityContext":{"capabilities":{"add":["NET_ADMIN"]},"runAsUser":1000},"workingDir":"/tmp"}`,
+		},
+		{
+			name: "list index and append",
+			spec: `{"volumeMounts":[{"name":"data","mountPath":"/data"}]}`,
+			set:  []string{"spec.volumeMounts.0.readOnly=true", "spec.volumeMounts.1.name=tmp"},
+			want: `{"volumeMounts":[{"mountPath":"/data","name":"data","readOnly":true},{"name":"tmp"}]}`,
+		},
+		{
+			name:    "index out of range",
+			spec:    `{"args":["a"]}`,
+			set:     []string{"spec.args.3=b"},
+			wantErr: true,
+		},
+		{
+			name:    "path through a scalar",
+			spec:    `{"image":"busybox"}`,
+			set:     []string{"spec.image.tag=latest"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := ParseEnv(tt.env)
+			if err != nil {
+				t.Fatalf("ParseEnv() error = %v", err)
+			}
+			set, err := ParseSet(tt.set)
+			if err != nil {
+				t.Fatalf("ParseSet() error = %v", err)
+			}
+
+			got, err := SpecOverrides{Env: env, Set: set}.Apply([]byte(tt.spec))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("Apply() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecWithOverrides(t *testing.T) {
+	p := Profile{ProfileName: "net", Profile: "netadmin"}
+	p.SetSpecOverrides(SpecOverrides{Set: []SpecAssignment{{Path: []string{"workingDir"}, Value: "/tmp"}}})
+
+	container, err := p.DebugContainer(context.Background())
+	if err != nil {
+		t.Fatalf("DebugContainer() error = %v", err)
+	}
+
+	if container.WorkingDir != "/tmp" {
+		t.Errorf("WorkingDir = %q, want /tmp", container.WorkingDir)
+	}
+	if container.SecurityContext == nil || len(container.SecurityContext.Capabilities.Add) != 2 {
+		t.Errorf("SecurityContext of the built-in profile got lost: %+v", container.SecurityContext)
+	}
+}
@@ -0,0 +1,305 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PolicyConfig configures the policy every debug session must satisfy
+type PolicyConfig struct {
+	// File is a YAML file with additional rules (e.g. maintained by the security team),
+	// environment variables get expanded
+	File  string       `koanf:"file" yaml:"file"`
+	Rules []PolicyRule `koanf:"rules" yaml:"rules"`
+}
+
+// PolicyRule is a CEL expression which must evaluate to true to allow a debug session
+type PolicyRule struct {
+	Name       string `koanf:"name" yaml:"name"`
+	Expression string `koanf:"expression" yaml:"expression" validate:"required"`
+	// Message is the reason shown when the rule denies a debug session
+	Message string `koanf:"message" yaml:"message"`
+}
+
+// policyFile is the format of PolicyConfig.File
+type policyFile struct {
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+// PolicyInput is everything a policy rule can check
+type PolicyInput struct {
+	Profile   *Profile
+	Spec      *corev1.Container
+	Pod       *corev1.Pod // nil if the target pod is unknown
+	Namespace string
+	Context   KubeContext
+}
+
+// PolicyDecision is the result of evaluating the policy
+type PolicyDecision struct {
+	Allowed bool
+	Rule    string // the rule which denied the debug session
+	Reason  string
+}
+
+// ErrPolicyDenied is returned (wrapped) if the policy denies a debug session
+var ErrPolicyDenied = errors.New("denied by policy")
+
+// policyEnv declares the variables available in policy expressions
+func policyEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("profile", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("spec", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("pod", cel.MapType(cel.StringType, cel.DynType)),
+		// namespace is a reserved word in CEL, the session details are grouped in request
+		cel.Variable("request", cel.MapType(cel.StringType, cel.StringType)),
+		ext.Strings(),
+	)
+}
+
+// Vars returns the variables of the policy expressions. spec and pod are the JSON
+// representation of the container spec and the target pod, empty if they are unknown.
+// request holds the namespace, the kubeconfig context, cluster and user.
+func (in *PolicyInput) Vars() (map[string]any, error) {
+	spec, err := toJSONMap(in.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("convert spec: %w", err)
+	}
+
+	pod, err := toJSONMap(in.Pod)
+	if err != nil {
+		return nil, fmt.Errorf("convert pod: %w", err)
+	}
+
+	p := in.Profile
+	sourceType := p.ProfileSource.Type
+	if p.GetSource() != nil {
+		sourceType = p.GetSource().Type()
+	}
+
+	profile, err := toJSONMap(map[string]any{
+		"name":             p.ProfileName,
+		"image":            p.Image,
+		"imagePullPolicy":  p.ImagePullPolicy,
+		"namespace":        p.Namespace,
+		"namespaces":       p.Namespaces,
+		"targetContainer":  p.TargetContainer,
+		"matchLabels":      p.MatchLabels,
+		"matchExpressions": p.MatchExpressions,
+		"fieldSelector":    p.FieldSelector,
+		"workload":         p.Workload,
+		"inheritEnvFrom":   p.InheritEnvFrom,
+		"contexts":         p.Contexts,
+		"clusters":         p.Clusters,
+		"sourceType":       sourceType,
+		"builtIn":          p.IsBuiltInProfile() || sourceType == SourceTypeBuiltIn,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("convert profile: %w", err)
+	}
+
+	return map[string]any{
+		"profile": profile,
+		"spec":    spec,
+		"pod":     pod,
+		"request": map[string]string{
+			"namespace": in.Namespace,
+			"context":   in.Context.Name,
+			"cluster":   in.Context.Cluster,
+			"user":      in.Context.User,
+		},
+	}, nil
+}
+
+// toJSONMap converts v to the map its JSON representation decodes to, nil becomes an empty map
+func toJSONMap(v any) (map[string]any, error) {
+	m := map[string]any{}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	if m == nil {
+		m = map[string]any{}
+	}
+
+	return m, nil
+}
+
+// PolicyRules returns the rules of the config followed by the rules of the policy file
+func PolicyRules() ([]PolicyRule, error) {
+	rules := slices.Clone(Config.Policy.Rules)
+
+	if Config.Policy.File == "" {
+		return rules, nil
+	}
+
+	data, err := os.ReadFile(os.ExpandEnv(Config.Policy.File))
+	if err != nil {
+		return nil, fmt.Errorf("read policy file: %w", err)
+	}
+
+	file := &policyFile{}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("parse policy file %q: %w", Config.Policy.File, err)
+	}
+
+	return append(rules, file.Rules...), nil
+}
+
+// Policy is a set of compiled policy rules
+type Policy struct {
+	rules    []PolicyRule
+	programs []cel.Program
+}
+
+// CompilePolicy compiles the rules, every expression must evaluate to a bool
+func CompilePolicy(rules []PolicyRule) (*Policy, error) {
+	env, err := policyEnv()
+	if err != nil {
+		return nil, fmt.Errorf("create CEL environment: %w", err)
+	}
+
+	policy := &Policy{rules: rules}
+
+	var errs []error
+	for i, r := range rules {
+		prg, err := compilePolicyRule(env, r)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", policyRuleName(i, r), err))
+			continue
+		}
+		policy.programs = append(policy.programs, prg)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return policy, nil
+}
+
+func compilePolicyRule(env *cel.Env, r PolicyRule) (cel.Program, error) {
+	if r.Expression == "" {
+		return nil, errors.New("expression is empty")
+	}
+
+	ast, issues := env.Compile(r.Expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	// dyn is checked when the rule gets evaluated, e.g. for spec.securityContext.privileged
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %s", ast.OutputType())
+	}
+
+	return env.Program(ast)
+}
+
+func policyRuleName(idx int, r PolicyRule) string {
+	if r.Name != "" {
+		return fmt.Sprintf("%q", r.Name)
+	}
+	return fmt.Sprintf("#%d", idx)
+}
+
+// Evaluate evaluates all rules in order, the first rule which evaluates to false denies the
+// debug session. A rule which can't be evaluated (e.g. it accesses a missing field) returns
+// an error, the debug session must not start then.
+func (p *Policy) Evaluate(vars map[string]any) (*PolicyDecision, error) {
+	for i, prg := range p.programs {
+		r := p.rules[i]
+
+		out, _, err := prg.Eval(vars)
+		if err != nil {
+			return nil, fmt.Errorf("evaluate policy rule %s: %w", policyRuleName(i, r), err)
+		}
+
+		allowed, ok := out.Value().(bool)
+		if !ok {
+			return nil, fmt.Errorf("evaluate policy rule %s: result %v is not a bool", policyRuleName(i, r), out.Value())
+		}
+
+		if !allowed {
+			reason := r.Message
+			if reason == "" {
+				reason = fmt.Sprintf("policy rule %s evaluated to false", policyRuleName(i, r))
+			}
+			return &PolicyDecision{Rule: r.Name, Reason: reason}, nil
+		}
+	}
+
+	return &PolicyDecision{Allowed: true}, nil
+}
+
+// EvaluatePolicy evaluates the configured policy for a debug session. Without rules every
+// debug session is allowed.
+func EvaluatePolicy(in *PolicyInput) (*PolicyDecision, error) {
+	rules, err := PolicyRules()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rules) == 0 {
+		return &PolicyDecision{Allowed: true}, nil
+	}
+
+	policy, err := CompilePolicy(rules)
+	if err != nil {
+		return nil, fmt.Errorf("compile policy: %w", err)
+	}
+
+	vars, err := in.Vars()
+	if err != nil {
+		return nil, err
+	}
+
+	return policy.Evaluate(vars)
+}
+
+// validatePolicy reports policy files which can't be read and rules which don't compile
+func validatePolicy(report *Report) {
+	env, err := policyEnv()
+	if err != nil {
+		report.Add(SeverityError, "", "policy", "create CEL environment: %v", err)
+		return
+	}
+
+	for i, r := range Config.Policy.Rules {
+		if _, err := compilePolicyRule(env, r); err != nil {
+			report.Add(SeverityError, "", fmt.Sprintf("policy.rules[%d].expression", i), "%v", err)
+		}
+	}
+
+	if Config.Policy.File == "" {
+		return
+	}
+
+	rules, err := PolicyRules()
+	if err != nil {
+		report.Add(SeverityError, "", "policy.file", "%v", err)
+		return
+	}
+
+	for i, r := range rules[len(Config.Policy.Rules):] {
+		if _, err := compilePolicyRule(env, r); err != nil {
+			report.Add(SeverityError, "", "policy.file", "rules[%d].expression: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestEvaluatePolicy(t *testing.T) {
+	input := &PolicyInput{
+		Profile: &Profile{ProfileName: "netadmin", Image: "nicolaka/netshoot:v0.13", ProfileSource: ProfileSourceConfig{Type: SourceTypeFile}},
+		Spec: &corev1.Container{SecurityContext: &corev1.SecurityContext{
+			Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}},
+		}},
+		Pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "webapp", Labels: map[string]string{"team": "payments"}},
+		},
+		Namespace: "payments",
+		Context:   KubeContext{Name: "prod-eu", Cluster: "prod", User: "alice"},
+	}
+
+	tests := []struct {
+		name       string
+		rules      []PolicyRule
+		input      *PolicyInput
+		wantAllow  bool
+		wantReason string
+		wantErr    string
+	}{
+		{
+			name:      "no rules",
+			wantAllow: true,
+		},
+		{
+			name: "all rules allow",
+			rules: []PolicyRule{
+				{Expression: `request.namespace == "payments"`},
+				{Expression: `request.user in ["alice", "bob"] && request.context.startsWith("prod-")`},
+				{Expression: `pod.metadata.labels.team == "payments"`},
+			},
+			wantAllow: true,
+		},
+		{
+			name: "denied with message",
+			rules: []PolicyRule{
+				{Name: "namespaces", Expression: `request.namespace != "kube-system"`},
+				{Name: "no-netadmin-in-prod", Expression: `!(request.cluster == "prod" && "NET_ADMIN" in spec.securityContext.capabilities.add)`, Message: "NET_ADMIN is not allowed in prod"},
+			},
+			wantReason: "NET_ADMIN is not allowed in prod",
+		},
+		{
+			name:       "denied without message",
+			rules:      []PolicyRule{{Name: "file-sources-only", Expression: `profile.sourceType == "git"`}},
+			wantReason: `policy rule "file-sources-only" evaluated to false`,
+		},
+		{
+			name:  "unknown pod",
+			rules: []PolicyRule{{Expression: `!has(pod.metadata) || pod.metadata.name != "webapp"`}},
+			input: &PolicyInput{
+				Profile: &Profile{ProfileName: "general"},
+				Spec:    &corev1.Container{SecurityContext: &corev1.SecurityContext{Privileged: ptr.To(true)}},
+			},
+			wantAllow: true,
+		},
+		{
+			name:    "missing field",
+			rules:   []PolicyRule{{Expression: `spec.securityContext.privileged`}},
+			wantErr: `evaluate policy rule #0: no such key: privileged`,
+		},
+		{
+			name:    "not a bool",
+			rules:   []PolicyRule{{Name: "namespace", Expression: `request.namespace`}},
+			wantErr: `rule "namespace": expression must evaluate to a bool, not string`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config = CustomDebugProfile{Policy: PolicyConfig{Rules: tt.rules}}
+
+			in := input
+			if tt.input != nil {
+				in = tt.input
+			}
+
+			decision, err := EvaluatePolicy(in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("EvaluatePolicy() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EvaluatePolicy() error = %v", err)
+			}
+
+			if decision.Allowed != tt.wantAllow || decision.Reason != tt.wantReason {
+				t.Errorf("EvaluatePolicy() = %+v, want allowed %v with reason %q", decision, tt.wantAllow, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestPolicyFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(file, []byte(`rules:
+  - name: no-prod
+    expression: request.cluster != "prod"
+    message: debugging in prod requires a break-glass profile
+  - expression: request.namespace +
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	Config = CustomDebugProfile{Policy: PolicyConfig{
+		File:  file,
+		Rules: []PolicyRule{{Expression: `request.user != ""`}},
+	}}
+
+	rules, err := PolicyRules()
+	if err != nil {
+		t.Fatalf("PolicyRules() error = %v", err)
+	}
+	if len(rules) != 3 || rules[1].Name != "no-prod" {
+		t.Fatalf("PolicyRules() = %+v, want the config rule followed by the file rules", rules)
+	}
+
+	report := &Report{}
+	validatePolicy(report)
+
+	if len(report.Findings) != 1 || report.Findings[0].Field != "policy.file" ||
+		!strings.HasPrefix(report.Findings[0].Message, "rules[1].expression: ") {
+		t.Errorf("validatePolicy() = %+v, want a single finding for the invalid file rule", report.Findings)
+	}
+}
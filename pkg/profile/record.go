@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+const (
+	// RecordOptional records debug sessions only with run --record (default)
+	RecordOptional = "optional"
+	// RecordAlways records every debug session of the profile
+	RecordAlways = "always"
+	// RecordNever refuses to record debug sessions of the profile
+	RecordNever = "never"
+)
+
+// RecordPolicies are the valid values of RecordConfig.Policy
+var RecordPolicies = []string{RecordOptional, RecordAlways, RecordNever}
+
+// RecordConfig configures the recording of the debug sessions of a profile
+type RecordConfig struct {
+	Policy string `koanf:"policy" yaml:"policy"` // optional (default), always or never
+	// Input also records the keystrokes, they aren't recorded by default as they may contain secrets
+	Input bool `koanf:"input" yaml:"input"`
+	// Redact are regular expressions whose matches are replaced in the recording
+	Redact []string `koanf:"redact" yaml:"redact"`
+}
+
+// RecordingConfig configures the recordings of all profiles
+type RecordingConfig struct {
+	// Dir of the recordings, environment variables get expanded (default: ~/.kube-dpm/recordings)
+	Dir string `koanf:"dir" yaml:"dir"`
+	// Redact are regular expressions whose matches are replaced in the recordings of all profiles
+	Redact []string `koanf:"redact" yaml:"redact"`
+}
+
+// RecordPolicy returns the recording policy of the profile
+func (p *Profile) RecordPolicy() string {
+	if p.Record.Policy == "" {
+		return RecordOptional
+	}
+	return p.Record.Policy
+}
+
+// ShouldRecord reports whether a debug session of the profile gets recorded,
+// requested is set by run --record
+func (p *Profile) ShouldRecord(requested bool) (bool, error) {
+	switch p.RecordPolicy() {
+	case RecordAlways:
+		return true, nil
+	case RecordNever:
+		if requested {
+			return false, fmt.Errorf("profile %q doesn't allow recording debug sessions", p.ProfileName)
+		}
+		return false, nil
+	case RecordOptional:
+		return requested, nil
+	default:
+		return false, fmt.Errorf("profile %q has an invalid record policy %q", p.ProfileName, p.Record.Policy)
+	}
+}
+
+// RedactPatterns returns the global and the profile redaction patterns
+func (p *Profile) RedactPatterns() ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+
+	for _, expr := range slices.Concat(Config.Recording.Redact, p.Record.Redact) {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("compile redact pattern %q: %w", expr, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	return patterns, nil
+}
+
+// RecordingsDir returns the configured directory of the recordings
+func RecordingsDir() string {
+	if Config.Recording.Dir != "" {
+		return os.ExpandEnv(Config.Recording.Dir)
+	}
+	return filepath.Join(os.Getenv("HOME"), ".kube-dpm", "recordings")
+}
+
+func validateRecord(p *Profile, report *Report) {
+	if !slices.Contains(RecordPolicies, p.RecordPolicy()) {
+		report.Add(SeverityError, p.ProfileName, "record.policy", "invalid record policy %q, must be one of %v", p.Record.Policy, RecordPolicies)
+	}
+
+	for i, expr := range p.Record.Redact {
+		if _, err := regexp.Compile(expr); err != nil {
+			report.Add(SeverityError, p.ProfileName, fmt.Sprintf("record.redact[%d]", i), "%v", err)
+		}
+	}
+}
+
+func validateRecording(report *Report) {
+	for i, expr := range Config.Recording.Redact {
+		if _, err := regexp.Compile(expr); err != nil {
+			report.Add(SeverityError, "", fmt.Sprintf("recording.redact[%d]", i), "%v", err)
+		}
+	}
+}
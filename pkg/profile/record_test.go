@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"testing"
+)
+
+func TestShouldRecord(t *testing.T) {
+	tests := []struct {
+		policy    string
+		requested bool
+		want      bool
+		wantErr   bool
+	}{
+		{policy: "", requested: false, want: false},
+		{policy: "", requested: true, want: true},
+		{policy: RecordOptional, requested: true, want: true},
+		{policy: RecordAlways, requested: false, want: true},
+		{policy: RecordNever, requested: false, want: false},
+		{policy: RecordNever, requested: true, wantErr: true},
+		{policy: "sometimes", requested: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		p := &Profile{ProfileName: "webapp", Record: RecordConfig{Policy: tt.policy}}
+
+		got, err := p.ShouldRecord(tt.requested)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ShouldRecord(%q, %t) error = %v, wantErr %t", tt.policy, tt.requested, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ShouldRecord(%q, %t) = %t, want %t", tt.policy, tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestRedactPatterns(t *testing.T) {
+	oldConfig := Config
+	t.Cleanup(func() { Config = oldConfig })
+
+	Config.Recording.Redact = []string{`password=\S+`}
+	p := &Profile{ProfileName: "webapp", Record: RecordConfig{Redact: []string{`Bearer \S+`}}}
+
+	patterns, err := p.RedactPatterns()
+	if err != nil {
+		t.Fatalf("RedactPatterns() error = %v", err)
+	}
+	if len(patterns) != 2 {
+		t.Fatalf("RedactPatterns() returned %d patterns, want 2", len(patterns))
+	}
+
+	p.Record.Redact = []string{`(`}
+	if _, err := p.RedactPatterns(); err == nil {
+		t.Errorf("RedactPatterns() with an invalid pattern succeeded")
+	}
+
+	report := &Report{}
+	validateRecord(&Profile{ProfileName: "webapp", Record: RecordConfig{Policy: "sometimes", Redact: []string{`(`}}}, report)
+	if len(report.Findings) != 2 {
+		t.Errorf("validateRecord() findings = %+v, want 2", report.Findings)
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Severity of a validation finding
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// FieldSpec is the field path of findings about the content of a custom profile spec,
+// e.g. a source which can't be fetched or a spec which can't be parsed
+const FieldSpec = "spec"
+
+// Finding is a single result of the validation of a profile
+type Finding struct {
+	Severity Severity `json:"severity"`
+	// Profile is empty for findings which don't belong to a single profile
+	Profile string `json:"profile,omitempty"`
+	// Field is the path of the field within the profile, e.g. "profileSource.git.url"
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f Finding) Error() string {
+	switch {
+	case f.Profile != "" && f.Field != "":
+		return fmt.Sprintf("profile %q: %s: %s", f.Profile, f.Field, f.Message)
+	case f.Profile != "":
+		return fmt.Sprintf("profile %q: %s", f.Profile, f.Message)
+	case f.Field != "":
+		return fmt.Sprintf("%s: %s", f.Field, f.Message)
+	default:
+		return f.Message
+	}
+}
+
+// Report collects all findings of a validation run
+type Report struct {
+	// Profiles are the names of all validated profiles
+	Profiles []string  `json:"profiles"`
+	Findings []Finding `json:"findings"`
+}
+
+// Add records a finding
+func (r *Report) Add(severity Severity, profile, field, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{
+		Severity: severity,
+		Profile:  profile,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Count returns the number of findings with the given severity
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed reports whether the validation failed. In strict mode warnings fail the validation as well.
+func (r *Report) Failed(strict bool) bool {
+	return r.Count(SeverityError) > 0 || (strict && r.Count(SeverityWarning) > 0)
+}
+
+// ProfileFindings returns the findings of a single profile, an empty name returns the findings
+// which don't belong to a profile
+func (r *Report) ProfileFindings(profile string) []Finding {
+	var findings []Finding
+	for _, f := range r.Findings {
+		if f.Profile == profile {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// Err returns all error findings except the ones about the spec content, which
+// can't be fixed in the configuration file and mustn't block using other profiles
+func (r *Report) Err() error {
+	var errs []error
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError && f.Field != FieldSpec {
+			errs = append(errs, f)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AddClusterReport records the checks of a cluster validation as findings.
+// Failed checks are errors, warnings are warnings and passed checks are infos.
+func (r *Report) AddClusterReport(c *ClusterReport) {
+	for _, check := range c.Checks {
+		severity := SeverityInfo
+		switch check.Status {
+		case CheckFail:
+			severity = SeverityError
+		case CheckWarn:
+			severity = SeverityWarning
+		}
+		r.Findings = append(r.Findings, Finding{
+			Severity: severity,
+			Profile:  c.Profile,
+			Field:    "cluster." + check.Name,
+			Message:  check.Message,
+		})
+	}
+}
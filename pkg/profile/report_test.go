@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	tests := []struct {
+		name           string
+		findings       []Finding
+		wantFailed     bool
+		wantStrictFail bool
+		wantErr        bool
+	}{
+		{
+			name:     "only infos",
+			findings: []Finding{{Severity: SeverityInfo, Profile: "cm", Field: FieldSpec}},
+		},
+		{
+			name:           "warning fails in strict mode",
+			findings:       []Finding{{Severity: SeverityWarning, Profile: "legacy", Field: "profile"}},
+			wantStrictFail: true,
+		},
+		{
+			name:           "spec error fails the validation but isn't a configuration error",
+			findings:       []Finding{{Severity: SeverityError, Profile: "git", Field: FieldSpec}},
+			wantFailed:     true,
+			wantStrictFail: true,
+		},
+		{
+			name:           "configuration error",
+			findings:       []Finding{{Severity: SeverityError, Profile: "file", Field: "profileSource.path"}},
+			wantFailed:     true,
+			wantStrictFail: true,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Report{Findings: tt.findings}
+
+			if got := r.Failed(false); got != tt.wantFailed {
+				t.Errorf("Failed(false) = %v, want %v", got, tt.wantFailed)
+			}
+			if got := r.Failed(true); got != tt.wantStrictFail {
+				t.Errorf("Failed(true) = %v, want %v", got, tt.wantStrictFail)
+			}
+			if err := r.Err(); (err != nil) != tt.wantErr {
+				t.Errorf("Err() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
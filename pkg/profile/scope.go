@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"path"
+)
+
+// KubeContext describes the active kubeconfig context a profile is evaluated against.
+type KubeContext struct {
+	Name    string // kubeconfig context name
+	Cluster string // cluster name referenced by the context
+	User    string // user (auth info) name referenced by the context
+}
+
+// MatchesContext reports whether the profile is usable in the given kubeconfig context.
+// Profiles without a contexts or clusters list match every context. Entries are
+// shell patterns as understood by path.Match (e.g. "prod-*").
+func (p *Profile) MatchesContext(kubeContext KubeContext) bool {
+	return matchesAny(p.Contexts, kubeContext.Name) && matchesAny(p.Clusters, kubeContext.Cluster)
+}
+
+// ProfilesForContext returns all profiles which are usable in the given kubeconfig context.
+func ProfilesForContext(profiles []Profile, kubeContext KubeContext) []Profile {
+	var scopedProfiles []Profile
+
+	for _, p := range profiles {
+		if p.MatchesContext(kubeContext) {
+			scopedProfiles = append(scopedProfiles, p)
+		}
+	}
+
+	return scopedProfiles
+}
+
+// matchesAny returns true if patterns is empty or at least one pattern matches name
+func matchesAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if pattern == name {
+			return true
+		}
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
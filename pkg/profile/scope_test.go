@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"testing"
+)
+
+func TestProfile_MatchesContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     Profile
+		kubeContext KubeContext
+		want        bool
+	}{
+		{
+			name:        "profile without scope matches every context",
+			profile:     Profile{ProfileName: "profile1"},
+			kubeContext: KubeContext{Name: "edge-1", Cluster: "edge"},
+			want:        true,
+		},
+		{
+			name:        "context listed",
+			profile:     Profile{ProfileName: "profile1", Contexts: []string{"monitoring-1", "monitoring-2"}},
+			kubeContext: KubeContext{Name: "monitoring-2", Cluster: "monitoring"},
+			want:        true,
+		},
+		{
+			name:        "context not listed",
+			profile:     Profile{ProfileName: "profile1", Contexts: []string{"monitoring-1"}},
+			kubeContext: KubeContext{Name: "edge-1", Cluster: "edge"},
+			want:        false,
+		},
+		{
+			name:        "context pattern",
+			profile:     Profile{ProfileName: "profile1", Contexts: []string{"monitoring-*"}},
+			kubeContext: KubeContext{Name: "monitoring-eu", Cluster: "monitoring"},
+			want:        true,
+		},
+		{
+			name:        "cluster listed",
+			profile:     Profile{ProfileName: "profile1", Clusters: []string{"monitoring"}},
+			kubeContext: KubeContext{Name: "admin@monitoring", Cluster: "monitoring"},
+			want:        true,
+		},
+		{
+			name: "context listed but cluster not",
+			profile: Profile{
+				ProfileName: "profile1",
+				Contexts:    []string{"admin@monitoring"},
+				Clusters:    []string{"monitoring"},
+			},
+			kubeContext: KubeContext{Name: "admin@monitoring", Cluster: "edge"},
+			want:        false,
+		},
+		{
+			name:        "no active context",
+			profile:     Profile{ProfileName: "profile1", Contexts: []string{"monitoring-*"}},
+			kubeContext: KubeContext{},
+			want:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.MatchesContext(tt.kubeContext); got != tt.want {
+				t.Errorf("MatchesContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfilesForContext(t *testing.T) {
+	profiles := []Profile{
+		{ProfileName: "everywhere"},
+		{ProfileName: "prometheus", Clusters: []string{"monitoring"}},
+		{ProfileName: "edge", Contexts: []string{"edge-*"}},
+	}
+
+	got := ProfilesForContext(profiles, KubeContext{Name: "edge-1", Cluster: "edge"})
+
+	if len(got) != 2 || got[0].ProfileName != "everywhere" || got[1].ProfileName != "edge" {
+		t.Errorf("ProfilesForContext() = %v, want [everywhere edge]", got)
+	}
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// LabelRequirement is a label selector expression like "track In (canary)"
+type LabelRequirement struct {
+	Key      string   `koanf:"key" yaml:"key" json:"key" validate:"required"`
+	Operator string   `koanf:"operator" yaml:"operator" json:"operator" validate:"required"` // In, NotIn, Exists or DoesNotExist
+	Values   []string `koanf:"values" yaml:"values" json:"values"`
+}
+
+// podSelectableFields are the fields of pods the API server supports in field selectors
+var podSelectableFields = []string{
+	"metadata.name",
+	"metadata.namespace",
+	"spec.nodeName",
+	"spec.restartPolicy",
+	"spec.schedulerName",
+	"spec.serviceAccountName",
+	"spec.hostNetwork",
+	"status.phase",
+	"status.podIP",
+	"status.podIPs",
+	"status.nominatedNodeName",
+}
+
+// labelSelector returns the matchLabels and matchExpressions of the profile as label selector
+func (p *Profile) labelSelector() *metav1.LabelSelector {
+	selector := &metav1.LabelSelector{MatchLabels: p.MatchLabels}
+
+	for _, r := range p.MatchExpressions {
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      r.Key,
+			Operator: metav1.LabelSelectorOperator(r.Operator),
+			Values:   r.Values,
+		})
+	}
+
+	return selector
+}
+
+// labelRequirements returns the matchExpressions of a label selector as profile fields
+func labelRequirements(selector *metav1.LabelSelector) []LabelRequirement {
+	var requirements []LabelRequirement
+
+	for _, r := range selector.MatchExpressions {
+		requirements = append(requirements, LabelRequirement{
+			Key:      r.Key,
+			Operator: string(r.Operator),
+			Values:   r.Values,
+		})
+	}
+
+	return requirements
+}
+
+// hasLabelSelector reports whether the profile selects pods by labels
+func (p *Profile) hasLabelSelector() bool {
+	return len(p.MatchLabels) > 0 || len(p.MatchExpressions) > 0
+}
+
+// FormatSelector formats everything selecting the target pods of the profile: the workload,
+// the label selector and the field selector
+func (p *Profile) FormatSelector() string {
+	var parts []string
+
+	if p.Workload != "" {
+		parts = append(parts, p.Workload)
+	}
+	if p.hasLabelSelector() {
+		parts = append(parts, metav1.FormatLabelSelector(p.labelSelector()))
+	}
+	if p.FieldSelector != "" {
+		parts = append(parts, p.FieldSelector)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func validateSelector(p *Profile, report *Report) {
+	if _, err := metav1.LabelSelectorAsSelector(p.labelSelector()); err != nil {
+		report.Add(SeverityError, p.ProfileName, "matchExpressions", "invalid label selector: %v", err)
+	}
+
+	if p.FieldSelector == "" {
+		return
+	}
+
+	selector, err := fields.ParseSelector(p.FieldSelector)
+	if err != nil {
+		report.Add(SeverityError, p.ProfileName, "fieldSelector", "invalid field selector: %v", err)
+		return
+	}
+
+	for _, r := range selector.Requirements() {
+		if !slices.Contains(podSelectableFields, r.Field) {
+			report.Add(SeverityError, p.ProfileName, "fieldSelector", "field %q isn't supported in field selectors of pods (supported: %s)",
+				r.Field, strings.Join(podSelectableFields, ", "))
+		}
+	}
+}
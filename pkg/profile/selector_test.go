@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodSelectorExpressions(t *testing.T) {
+	p := Profile{
+		MatchLabels: map[string]string{"app": "webapp"},
+		MatchExpressions: []LabelRequirement{
+			{Key: "track", Operator: "In", Values: []string{"canary", "beta"}},
+			{Key: "legacy", Operator: "DoesNotExist"},
+		},
+	}
+	p.SetWorkloadSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"run": "webapp"}})
+
+	got, err := p.PodSelector()
+	if err != nil {
+		t.Fatalf("PodSelector() error = %v", err)
+	}
+
+	if want := "app=webapp,!legacy,run=webapp,track in (beta,canary)"; got.String() != want {
+		t.Errorf("PodSelector() = %q, want %q", got.String(), want)
+	}
+}
+
+func TestFormatSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		want    string
+	}{
+		{name: "no selector"},
+		{
+			name:    "matchLabels",
+			profile: Profile{MatchLabels: map[string]string{"app": "webapp"}},
+			want:    "app=webapp",
+		},
+		{
+			name: "expressions and fields",
+			profile: Profile{
+				MatchExpressions: []LabelRequirement{{Key: "track", Operator: "NotIn", Values: []string{"stable"}}},
+				FieldSelector:    "spec.nodeName=node-1",
+			},
+			want: "track notin (stable); spec.nodeName=node-1",
+		},
+		{
+			name:    "workload",
+			profile: Profile{Workload: "sts/db", FieldSelector: "status.phase=Running"},
+			want:    "sts/db; status.phase=Running",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.FormatSelector(); got != tt.want {
+				t.Errorf("FormatSelector() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			profile: Profile{
+				MatchExpressions: []LabelRequirement{
+					{Key: "track", Operator: "In", Values: []string{"canary"}},
+					{Key: "tier", Operator: "Exists"},
+				},
+				FieldSelector: "status.phase=Running,spec.nodeName!=node-1",
+			},
+		},
+		{
+			name:    "In without values",
+			profile: Profile{MatchExpressions: []LabelRequirement{{Key: "track", Operator: "In"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Exists with values",
+			profile: Profile{MatchExpressions: []LabelRequirement{{Key: "track", Operator: "Exists", Values: []string{"canary"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown operator",
+			profile: Profile{MatchExpressions: []LabelRequirement{{Key: "track", Operator: "Like", Values: []string{"can*"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid field selector",
+			profile: Profile{FieldSelector: "status.phase"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported field",
+			profile: Profile{FieldSelector: "spec.priority=1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &Report{}
+			tt.profile.ProfileName = "test"
+			validateSelector(&tt.profile, report)
+
+			if got := report.Count(SeverityError) > 0; got != tt.wantErr {
+				t.Errorf("validateSelector() findings = %v, wantErr %v", report.Findings, tt.wantErr)
+			}
+		})
+	}
+}
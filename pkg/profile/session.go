@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	// SessionContainerPrefix is the name prefix of the ephemeral containers created by dpm
+	SessionContainerPrefix = "dpm-"
+
+	// SessionAnnotationPrefix is the prefix of the pod annotation with the metadata of a
+	// debug session, the name of the annotation is the name of the ephemeral container
+	SessionAnnotationPrefix = "kubectl-dpm.bavarianbidi.github.io/"
+
+	sessionSuffixLength = 5
+)
+
+// SessionsConfig configures how debug sessions are tagged in the cluster
+type SessionsConfig struct {
+	// Annotate annotates the target pod with the metadata of every debug session,
+	// this needs the permission to patch pods
+	Annotate bool `koanf:"annotate" yaml:"annotate"`
+	// Limits of all debug sessions, the limits of a profile can only be stricter
+	Limits SessionLimits `koanf:"limits" yaml:"limits"`
+}
+
+// SessionMetadata is stored in the session annotation of the target pod
+type SessionMetadata struct {
+	Profile    string    `json:"profile"`
+	SpecDigest string    `json:"specDigest,omitempty"`
+	User       string    `json:"user,omitempty"`
+	StartTime  time.Time `json:"startTime"`
+	// MaxDuration and IdleTimeout are the limits of the session, e.g. 1h0m0s
+	MaxDuration string `json:"maxDuration,omitempty"`
+	IdleTimeout string `json:"idleTimeout,omitempty"`
+}
+
+// Session is an ephemeral container, usually created by dpm
+type Session struct {
+	Namespace string
+	Pod       string
+	Container string
+	Image     string
+	TTY       bool
+	// Profile is the profile of the annotation, or the profile name part of the container name
+	Profile   string
+	Metadata  *SessionMetadata // nil if the pod isn't annotated
+	State     string
+	Running   bool
+	StartTime time.Time // zero if unknown
+}
+
+var invalidContainerNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// SessionContainerName returns a unique name for the ephemeral container of a debug session
+// with the profile, e.g. dpm-webapp-x7k2p
+func SessionContainerName(profileName string) string {
+	return SessionContainerPrefix + sessionName(profileName) + "-" + rand.String(sessionSuffixLength)
+}
+
+// sessionName returns the sanitized profile name part of a session container name
+func sessionName(profileName string) string {
+	name := strings.Trim(invalidContainerNameChars.ReplaceAllString(strings.ToLower(profileName), "-"), "-")
+
+	// container names are DNS labels with at most 63 characters
+	maxLength := 63 - len(SessionContainerPrefix) - 1 - sessionSuffixLength
+	if len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-")
+	}
+	if name == "" {
+		name = "session"
+	}
+
+	return name
+}
+
+// IsSessionContainer reports whether the ephemeral container was created by dpm
+func IsSessionContainer(name string) bool {
+	return strings.HasPrefix(name, SessionContainerPrefix)
+}
+
+// SessionAnnotation returns the annotation key and value with the metadata of a debug session
+func SessionAnnotation(container string, m *SessionMetadata) (string, string, error) {
+	value, err := json.Marshal(m)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal session metadata: %w", err)
+	}
+	return SessionAnnotationPrefix + container, string(value), nil
+}
+
+// PodSessions returns the debug sessions of the pod in the order they were created
+func PodSessions(pod *corev1.Pod) []Session {
+	var sessions []Session
+
+	for _, s := range PodEphemeralContainers(pod) {
+		if IsSessionContainer(s.Container) {
+			sessions = append(sessions, s)
+		}
+	}
+
+	return sessions
+}
+
+// PodEphemeralContainers returns all ephemeral containers of the pod, including the ones
+// not created by dpm (e.g. by kubectl debug), in the order they were created.
+// The profile of ephemeral containers not created by dpm is empty.
+func PodEphemeralContainers(pod *corev1.Pod) []Session {
+	var sessions []Session
+
+	for _, c := range pod.Spec.EphemeralContainers {
+		s := Session{
+			Namespace: pod.Namespace,
+			Pod:       pod.Name,
+			Container: c.Name,
+			Image:     c.Image,
+			TTY:       c.TTY,
+			State:     "Pending",
+		}
+
+		if IsSessionContainer(c.Name) {
+			s.Profile = sessionProfileFromName(c.Name)
+		}
+
+		if value, ok := pod.Annotations[SessionAnnotationPrefix+c.Name]; ok {
+			m := &SessionMetadata{}
+			if err := json.Unmarshal([]byte(value), m); err == nil {
+				s.Metadata = m
+				s.Profile = m.Profile
+				s.StartTime = m.StartTime
+			}
+		}
+
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name == c.Name {
+				s.setState(&status.State)
+			}
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions
+}
+
+func (s *Session) setState(state *corev1.ContainerState) {
+	var startedAt time.Time
+
+	switch {
+	case state.Running != nil:
+		s.State = "Running"
+		s.Running = true
+		startedAt = state.Running.StartedAt.Time
+	case state.Terminated != nil:
+		s.State = fmt.Sprintf("Terminated: %s (exit %d)", state.Terminated.Reason, state.Terminated.ExitCode)
+		startedAt = state.Terminated.StartedAt.Time
+	case state.Waiting != nil:
+		s.State = "Waiting: " + state.Waiting.Reason
+	}
+
+	if s.StartTime.IsZero() {
+		s.StartTime = startedAt
+	}
+}
+
+// sessionProfileFromName returns the (sanitized) profile name part of a session container name
+func sessionProfileFromName(name string) string {
+	name = strings.TrimPrefix(name, SessionContainerPrefix)
+	if len(name) > sessionSuffixLength+1 {
+		name = name[:len(name)-sessionSuffixLength-1]
+	}
+	return name
+}
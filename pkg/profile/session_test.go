@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestSessionContainerName(t *testing.T) {
+	tests := map[string]string{
+		"webapp":                  "dpm-webapp-",
+		"Team_A/Web App":          "dpm-team-a-web-app-",
+		"--":                      "dpm-session-",
+		strings.Repeat("a", 70):   "dpm-" + strings.Repeat("a", 53) + "-",
+		strings.Repeat("ab-", 30): "dpm-" + strings.TrimRight(strings.Repeat("ab-", 30)[:53], "-") + "-",
+	}
+
+	for profileName, wantPrefix := range tests {
+		name := SessionContainerName(profileName)
+
+		if !strings.HasPrefix(name, wantPrefix) || len(name) != len(wantPrefix)+sessionSuffixLength {
+			t.Errorf("SessionContainerName(%q) = %q, want %q followed by a random suffix", profileName, name, wantPrefix)
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			t.Errorf("SessionContainerName(%q) = %q is not a valid container name: %v", profileName, name, errs)
+		}
+		if !IsSessionContainer(name) {
+			t.Errorf("IsSessionContainer(%q) = false", name)
+		}
+	}
+
+	if SessionContainerName("webapp") == SessionContainerName("webapp") {
+		t.Errorf("SessionContainerName() returns the same name twice")
+	}
+}
+
+func TestPodSessions(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	key, value, err := SessionAnnotation("dpm-webapp-x7k2p", &SessionMetadata{
+		Profile: "webapp", SpecDigest: "sha256:abc", User: "alice", StartTime: start,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "webapp-0", Namespace: "default", Annotations: map[string]string{key: value}},
+		Spec: corev1.PodSpec{
+			EphemeralContainers: []corev1.EphemeralContainer{
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger-abcde", Image: "busybox"}},
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "dpm-webapp-x7k2p", Image: "nicolaka/netshoot"}},
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "dpm-netadmin-q8z4m", Image: "busybox"}},
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "dpm-general-b5n7r", Image: "busybox"}},
+			},
+		},
+		Status: corev1.PodStatus{
+			EphemeralContainerStatuses: []corev1.ContainerStatus{
+				{Name: "dpm-webapp-x7k2p", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(start.Add(time.Second))}}},
+				{Name: "dpm-netadmin-q8z4m", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Reason: "Completed", ExitCode: 0, StartedAt: metav1.NewTime(start.Add(time.Hour)),
+				}}},
+			},
+		},
+	}
+
+	want := []Session{
+		{Container: "dpm-webapp-x7k2p", Profile: "webapp", State: "Running", Running: true, StartTime: start},
+		{Container: "dpm-netadmin-q8z4m", Profile: "netadmin", State: "Terminated: Completed (exit 0)", StartTime: start.Add(time.Hour)},
+		{Container: "dpm-general-b5n7r", Profile: "general", State: "Pending"},
+	}
+
+	got := PodSessions(pod)
+	if len(got) != len(want) {
+		t.Fatalf("PodSessions() = %+v, want %d sessions", got, len(want))
+	}
+
+	for i, w := range want {
+		g := got[i]
+		if g.Container != w.Container || g.Profile != w.Profile || g.State != w.State || g.Running != w.Running ||
+			!g.StartTime.Equal(w.StartTime) || g.Pod != "webapp-0" || g.Namespace != "default" {
+			t.Errorf("PodSessions()[%d] = %+v, want %+v", i, g, w)
+		}
+	}
+
+	if got[0].Metadata == nil || got[0].Metadata.User != "alice" || got[0].Metadata.SpecDigest != "sha256:abc" {
+		t.Errorf("PodSessions()[0].Metadata = %+v, want the annotation", got[0].Metadata)
+	}
+}
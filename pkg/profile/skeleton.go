@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Skeleton is a generated profile together with the custom profile spec it refers to.
+type Skeleton struct {
+	// Origin describes where the skeleton was generated from (e.g. "deployment/webapp").
+	Origin  string
+	Profile Profile
+	Spec    []byte
+}
+
+// SkeletonOptions control which parts of the target container are mirrored into the custom profile spec.
+type SkeletonOptions struct {
+	// Env mirrors env and envFrom of the target container.
+	Env bool
+	// SecurityContext mirrors the securityContext of the target container.
+	SecurityContext bool
+}
+
+// NewSkeleton generates a profile for debugging the given container of a workload.
+// The custom profile spec mirrors the volumeMounts (and depending on opts more) of
+// the container and gets referenced as file source below specDir.
+func NewSkeleton(origin, name, namespace string, selector *metav1.LabelSelector, container *corev1.Container, specDir string, opts SkeletonOptions) (Skeleton, error) {
+	debugContainer := corev1.Container{
+		VolumeMounts: container.VolumeMounts,
+	}
+
+	if opts.Env {
+		debugContainer.Env = container.Env
+		debugContainer.EnvFrom = container.EnvFrom
+	}
+
+	if opts.SecurityContext {
+		debugContainer.SecurityContext = container.SecurityContext
+	}
+
+	spec, err := json.MarshalIndent(debugContainer, "", "  ")
+	if err != nil {
+		return Skeleton{}, fmt.Errorf("marshal profile spec for %s: %w", origin, err)
+	}
+
+	p := Profile{
+		ProfileName: name,
+		ProfileSource: ProfileSourceConfig{
+			Type: SourceTypeFile,
+			Path: filepath.Join(specDir, name+".json"),
+		},
+		Namespace:       namespace,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		TargetContainer: container.Name,
+	}
+
+	if selector != nil {
+		p.MatchLabels = selector.MatchLabels
+		p.MatchExpressions = labelRequirements(selector)
+	}
+
+	return Skeleton{Origin: origin, Profile: p, Spec: spec}, nil
+}
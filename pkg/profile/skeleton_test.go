@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewSkeleton(t *testing.T) {
+	container := &corev1.Container{
+		Name:  "webapp",
+		Image: "webapp:v1",
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "app-config", MountPath: "/app/config", ReadOnly: true},
+		},
+	}
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"run": "webapp"}}
+
+	got, err := NewSkeleton("deployment/webapp", "webapp", "default", selector, container, "/profiles", SkeletonOptions{})
+	if err != nil {
+		t.Fatalf("NewSkeleton() error = %v", err)
+	}
+
+	wantProfile := Profile{
+		ProfileName:     "webapp",
+		ProfileSource:   ProfileSourceConfig{Type: SourceTypeFile, Path: "/profiles/webapp.json"},
+		Namespace:       "default",
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		TargetContainer: "webapp",
+		MatchLabels:     map[string]string{"run": "webapp"},
+	}
+	if !reflect.DeepEqual(got.Profile, wantProfile) {
+		t.Errorf("NewSkeleton() profile = %+v, want %+v", got.Profile, wantProfile)
+	}
+
+	var spec corev1.Container
+	if err := json.Unmarshal(got.Spec, &spec); err != nil {
+		t.Fatalf("unmarshal spec: %v", err)
+	}
+	if !reflect.DeepEqual(spec.VolumeMounts, container.VolumeMounts) || spec.Image != "" || spec.Name != "" {
+		t.Errorf("NewSkeleton() spec = %s, want only the volumeMounts of the container", got.Spec)
+	}
+}
+
+func TestNewSkeleton_Options(t *testing.T) {
+	runAsUser := int64(1000)
+	container := &corev1.Container{
+		Name: "webapp",
+		Env:  []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}},
+		EnvFrom: []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "webapp"}}},
+		},
+		SecurityContext: &corev1.SecurityContext{RunAsUser: &runAsUser},
+	}
+
+	tests := []struct {
+		name                string
+		opts                SkeletonOptions
+		wantEnv             bool
+		wantSecurityContext bool
+	}{
+		{name: "volumeMounts only", opts: SkeletonOptions{}},
+		{name: "env", opts: SkeletonOptions{Env: true}, wantEnv: true},
+		{name: "securityContext", opts: SkeletonOptions{SecurityContext: true}, wantSecurityContext: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSkeleton("pod/webapp", "webapp", "default", nil, container, "/profiles", tt.opts)
+			if err != nil {
+				t.Fatalf("NewSkeleton() error = %v", err)
+			}
+
+			var spec corev1.Container
+			if err := json.Unmarshal(got.Spec, &spec); err != nil {
+				t.Fatalf("unmarshal spec: %v", err)
+			}
+
+			if gotEnv := spec.Env != nil && spec.EnvFrom != nil; gotEnv != tt.wantEnv {
+				t.Errorf("NewSkeleton() spec has env = %v, want %v", gotEnv, tt.wantEnv)
+			}
+			if gotSC := spec.SecurityContext != nil; gotSC != tt.wantSecurityContext {
+				t.Errorf("NewSkeleton() spec has securityContext = %v, want %v", gotSC, tt.wantSecurityContext)
+			}
+		})
+	}
+}
+
+func TestNewSkeleton_Selector(t *testing.T) {
+	expressions := []metav1.LabelSelectorRequirement{
+		{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"webapp"}},
+		{Key: "legacy", Operator: metav1.LabelSelectorOpDoesNotExist},
+	}
+	requirements := []LabelRequirement{
+		{Key: "app", Operator: "In", Values: []string{"webapp"}},
+		{Key: "legacy", Operator: "DoesNotExist"},
+	}
+
+	tests := []struct {
+		name                 string
+		selector             *metav1.LabelSelector
+		wantMatchLabels      map[string]string
+		wantMatchExpressions []LabelRequirement
+	}{
+		{name: "no selector"},
+		{
+			name:            "matchLabels",
+			selector:        &metav1.LabelSelector{MatchLabels: map[string]string{"run": "webapp"}},
+			wantMatchLabels: map[string]string{"run": "webapp"},
+		},
+		{
+			name:                 "matchExpressions only",
+			selector:             &metav1.LabelSelector{MatchExpressions: expressions},
+			wantMatchExpressions: requirements,
+		},
+		{
+			name:                 "matchLabels and matchExpressions",
+			selector:             &metav1.LabelSelector{MatchLabels: map[string]string{"run": "webapp"}, MatchExpressions: expressions},
+			wantMatchLabels:      map[string]string{"run": "webapp"},
+			wantMatchExpressions: requirements,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSkeleton("deployment/webapp", "webapp", "default", tt.selector, &corev1.Container{Name: "webapp"}, "/profiles", SkeletonOptions{})
+			if err != nil {
+				t.Fatalf("NewSkeleton() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got.Profile.MatchLabels, tt.wantMatchLabels) {
+				t.Errorf("NewSkeleton() matchLabels = %v, want %v", got.Profile.MatchLabels, tt.wantMatchLabels)
+			}
+			if !reflect.DeepEqual(got.Profile.MatchExpressions, tt.wantMatchExpressions) {
+				t.Errorf("NewSkeleton() matchExpressions = %+v, want %+v", got.Profile.MatchExpressions, tt.wantMatchExpressions)
+			}
+
+			// the profile must select exactly the pods of the workload
+			if tt.selector != nil && !reflect.DeepEqual(got.Profile.labelSelector(), tt.selector) {
+				t.Errorf("NewSkeleton() label selector = %+v, want %+v", got.Profile.labelSelector(), tt.selector)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ package profile
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	kubectldebug "k8s.io/kubectl/pkg/cmd/debug"
 )
@@ -16,11 +17,9 @@ type BuiltInProfileSource struct {
 	profileName string
 }
 
-// NewBuiltInProfileSource creates a new built-in profile source.
-// Returns an error if the profile name is not a recognized built-in profile.
-func NewBuiltInProfileSource(name string) (*BuiltInProfileSource, error) {
-	// Validate it's a known built-in profile
-	validNames := []string{
+// BuiltInProfileNames returns the names of all profiles built into kubectl debug.
+func BuiltInProfileNames() []string {
+	return []string{
 		// SA1019: ProfileLegacy is deprecated: legacyProfile is planned to be removed in v1.39
 		// nolint:staticcheck
 		kubectldebug.ProfileLegacy,
@@ -30,16 +29,15 @@ func NewBuiltInProfileSource(name string) (*BuiltInProfileSource, error) {
 		kubectldebug.ProfileNetadmin,
 		kubectldebug.ProfileSysadmin,
 	}
+}
 
-	valid := false
-	for _, v := range validNames {
-		if name == v {
-			valid = true
-			break
-		}
-	}
+// NewBuiltInProfileSource creates a new built-in profile source.
+// Returns an error if the profile name is not a recognized built-in profile.
+func NewBuiltInProfileSource(name string) (*BuiltInProfileSource, error) {
+	// Validate it's a known built-in profile
+	validNames := BuiltInProfileNames()
 
-	if !valid {
+	if !slices.Contains(validNames, name) {
 		return nil, fmt.Errorf("unknown built-in profile: %s (valid profiles: %v)", name, validNames)
 	}
 
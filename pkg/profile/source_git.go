@@ -5,9 +5,11 @@ package profile
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -41,23 +43,118 @@ func NewGitProfileSource(url, ref, path string) *GitProfileSource {
 }
 
 // GetSpec clones the Git repository and returns the JSON specification from the specified path.
+// Profiles sharing a repository and ref share a single clone (see CleanupGitClones).
 func (g *GitProfileSource) GetSpec(ctx context.Context) ([]byte, error) {
+	repoDir, err := cloneOnce(ctx, g.url, g.ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the profile file
+	profilePath := filepath.Join(repoDir, g.path)
+	data, err := os.ReadFile(profilePath)
+	if err != nil {
+		return nil, fmt.Errorf("read profile file %q from git repo: %w", g.path, err)
+	}
+
+	// Validate it's valid JSON representing a PodSpec
+	var podSpec corev1.PodSpec
+	if err := json.Unmarshal(data, &podSpec); err != nil {
+		return nil, fmt.Errorf("invalid JSON PodSpec in git repo %s@%s:%s: %w", g.url, g.ref, g.path, err)
+	}
+
+	return data, nil
+}
+
+// gitClone is the shared clone of a repository and ref
+type gitClone struct {
+	mu   sync.Mutex
+	done bool
+	dir  string
+	err  error
+}
+
+// gitClones caches the clones of all git sources by repository and ref
+var gitClones = struct {
+	sync.Mutex
+	clones map[string]*gitClone
+}{clones: map[string]*gitClone{}}
+
+// cloneOnce clones the repository at ref once and returns the directory of the clone.
+// Concurrent callers for the same repository and ref wait for the first clone.
+func cloneOnce(ctx context.Context, url, ref string) (string, error) {
+	key := url + "@" + ref
+
+	gitClones.Lock()
+	c, ok := gitClones.clones[key]
+	if !ok {
+		c = &gitClone{}
+		gitClones.clones[key] = c
+	}
+	gitClones.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.done {
+		dir, err := cloneRepository(ctx, url, ref)
+		// a cancelled clone may be retried with another context
+		if err != nil && ctx.Err() != nil {
+			return "", err
+		}
+		c.dir, c.err, c.done = dir, err, true
+	}
+
+	return c.dir, c.err
+}
+
+// CleanupGitClones removes the clones of all git sources
+func CleanupGitClones() {
+	gitClones.Lock()
+	defer gitClones.Unlock()
+
+	for _, c := range gitClones.clones {
+		c.mu.Lock()
+		if c.dir != "" {
+			_ = os.RemoveAll(c.dir)
+		}
+		c.mu.Unlock()
+	}
+
+	gitClones.clones = map[string]*gitClone{}
+}
+
+// cloneRepository clones the repository at ref into a new temporary directory.
+// ref is looked up as branch first and as tag if there is no such branch.
+func cloneRepository(ctx context.Context, url, ref string) (string, error) {
+	dir, err := cloneReference(ctx, url, plumbing.NewBranchReferenceName(ref))
+	if errors.Is(err, plumbing.ErrReferenceNotFound) || errors.As(err, new(git.NoMatchingRefSpecError)) {
+		dir, err = cloneReference(ctx, url, plumbing.NewTagReferenceName(ref))
+	}
+	if err != nil {
+		return "", fmt.Errorf("clone git repository %s@%s: %w", url, ref, err)
+	}
+
+	return dir, nil
+}
+
+// cloneReference clones the repository at the branch or tag refName into a new temporary directory
+func cloneReference(ctx context.Context, url string, refName plumbing.ReferenceName) (string, error) {
 	// Create a temporary directory for cloning
 	tmpDir, err := os.MkdirTemp("", "kubectl-dpm-git-*")
 	if err != nil {
-		return nil, fmt.Errorf("create temp directory: %w", err)
+		return "", fmt.Errorf("create temp directory: %w", err)
 	}
-	defer os.RemoveAll(tmpDir)
 
 	// Prepare clone options.
 	// Note: go-git does not support fetching individual files without cloning the repository.
 	// We use a shallow clone (Depth=1) to fetch only the latest commit and SingleBranch=true
-	// to clone only the specified branch, minimizing bandwidth and storage impact.
+	// to clone only the specified branch or tag, minimizing bandwidth and storage impact.
 	cloneOpts := &git.CloneOptions{
-		URL:           g.url,
+		URL:           url,
 		Depth:         1,    // Shallow clone: only fetch the latest commit
-		SingleBranch:  true, // Only clone the specified branch, not all branches
-		ReferenceName: plumbing.NewBranchReferenceName(g.ref),
+		SingleBranch:  true, // Only clone the specified reference, not all branches
+		ReferenceName: refName,
 	}
 
 	// Check for Git personal access token for private repositories
@@ -69,25 +166,12 @@ func (g *GitProfileSource) GetSpec(ctx context.Context) ([]byte, error) {
 	}
 
 	// Clone the repository
-	_, err = git.PlainCloneContext(ctx, tmpDir, false, cloneOpts)
-	if err != nil {
-		return nil, fmt.Errorf("clone git repository %s@%s: %w", g.url, g.ref, err)
-	}
-
-	// Read the profile file
-	profilePath := filepath.Join(tmpDir, g.path)
-	data, err := os.ReadFile(profilePath)
-	if err != nil {
-		return nil, fmt.Errorf("read profile file %q from git repo: %w", g.path, err)
+	if _, err := git.PlainCloneContext(ctx, tmpDir, false, cloneOpts); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return "", err
 	}
 
-	// Validate it's valid JSON representing a PodSpec
-	var podSpec corev1.PodSpec
-	if err := json.Unmarshal(data, &podSpec); err != nil {
-		return nil, fmt.Errorf("invalid JSON PodSpec in git repo %s@%s:%s: %w", g.url, g.ref, g.path, err)
-	}
-
-	return data, nil
+	return tmpDir, nil
 }
 
 // Type returns the source type identifier.
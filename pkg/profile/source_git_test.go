@@ -372,3 +372,71 @@ func TestGitProfileSource_GetSpec_ContextCancellation(t *testing.T) {
 		t.Logf("Expected error with cancelled context: %v", err)
 	}
 }
+
+func TestGitProfileSource_GetSpec_Tag(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping tag test in short mode")
+	}
+
+	const tagged = `{"env":[{"name":"VERSION","value":"v1"}]}`
+	repoPath := setupTestGitRepo(t, tagged, "profile.json")
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("failed to open git repo: %v", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("failed to get HEAD: %v", err)
+	}
+
+	signature := &object.Signature{Name: "Test User", Email: "test@example.com"}
+	if _, err := repo.CreateTag("v1.0.0", head.Hash(), nil); err != nil {
+		t.Fatalf("failed to create lightweight tag: %v", err)
+	}
+	if _, err := repo.CreateTag("v1.1.0", head.Hash(), &git.CreateTagOptions{Tagger: signature, Message: "v1.1.0"}); err != nil {
+		t.Fatalf("failed to create annotated tag: %v", err)
+	}
+
+	// main moves on after the tags
+	if err := os.WriteFile(filepath.Join(repoPath, "profile.json"), []byte(testValidProfile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add("profile.json"); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	if _, err := worktree.Commit("Update profile", &git.CommitOptions{Author: signature}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{name: "branch", ref: "main", want: testValidProfile},
+		{name: "lightweight tag", ref: "v1.0.0", want: tagged},
+		{name: "annotated tag", ref: "v1.1.0", want: tagged},
+		{name: "unknown ref", ref: "v2.0.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(CleanupGitClones)
+
+			spec, err := NewGitProfileSource(repoPath, tt.ref, "profile.json").GetSpec(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GitProfileSource.GetSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(spec) != tt.want {
+				t.Errorf("GitProfileSource.GetSpec() = %s, want %s", spec, tt.want)
+			}
+		})
+	}
+}
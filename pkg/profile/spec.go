@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	kubectldebug "k8s.io/kubectl/pkg/cmd/debug"
+	"k8s.io/utils/ptr"
+)
+
+// DebugContainer returns the partial container spec the profile applies to the debug container.
+// Built-in profiles are approximated by the security settings kubectl debug applies for them.
+// The profile source must be instantiated (see ValidateProfile and InitializeConfigMapSource).
+func (p *Profile) DebugContainer(ctx context.Context) (*corev1.Container, error) {
+	spec, err := p.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	container := &corev1.Container{}
+	if err := json.Unmarshal(spec, container); err != nil {
+		return nil, fmt.Errorf("parse profile spec: %w", err)
+	}
+
+	return container, nil
+}
+
+// SpecDigest returns the sha256 digest of the resolved profile spec, e.g. to record
+// which version of a git or configmap source got used
+func (p *Profile) SpecDigest(ctx context.Context) (string, error) {
+	spec, err := p.Spec(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(spec)), nil
+}
+
+// Spec returns the JSON spec of the profile with the inherited environment and the spec
+// overrides applied, for built-in profiles the JSON of BuiltInDebugContainer
+func (p *Profile) Spec(ctx context.Context) ([]byte, error) {
+	spec, err := p.baseSpec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.PatchSpec(spec)
+}
+
+// SpecPatched reports whether the spec is changed at run time by the inherited environment
+// or the spec overrides
+func (p *Profile) SpecPatched() bool {
+	return p.inheritedEnv.isSet() || p.specOverrides.IsSet()
+}
+
+// PatchSpec applies the inherited environment and the spec overrides to the JSON spec,
+// a nil spec returns only the changes
+func (p *Profile) PatchSpec(spec []byte) ([]byte, error) {
+	var err error
+
+	if p.inheritedEnv.isSet() {
+		if spec, err = p.inheritedEnv.apply(spec); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.specOverrides.IsSet() {
+		return p.specOverrides.Apply(spec)
+	}
+
+	return spec, nil
+}
+
+// baseSpec returns the raw profile spec, for built-in profiles the JSON of BuiltInDebugContainer
+func (p *Profile) baseSpec(ctx context.Context) ([]byte, error) {
+	switch {
+	case p.GetSource() != nil && p.GetSource().Type() == SourceTypeBuiltIn:
+		builtIn, ok := p.GetSource().(*BuiltInProfileSource)
+		if !ok {
+			return nil, errors.New("internal error: built-in source type assertion failed")
+		}
+		return json.Marshal(BuiltInDebugContainer(builtIn.ProfileName()))
+	case p.GetSource() != nil:
+		spec, err := p.GetSource().GetSpec(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get spec: %w", err)
+		}
+		return spec, nil
+	case p.Profile != "" && slices.Contains(BuiltInProfileNames(), p.Profile):
+		return json.Marshal(BuiltInDebugContainer(p.Profile))
+	case p.Profile != "":
+		spec, err := os.ReadFile(os.ExpandEnv(p.Profile))
+		if err != nil {
+			return nil, fmt.Errorf("read profile file %q: %w", p.Profile, err)
+		}
+		return spec, nil
+	default:
+		return nil, fmt.Errorf("profile %q has no profile source", p.ProfileName)
+	}
+}
+
+// BuiltInDebugContainer returns the security settings kubectl debug applies to an
+// ephemeral container for the given built-in profile.
+func BuiltInDebugContainer(name string) *corev1.Container {
+	container := &corev1.Container{}
+
+	switch name {
+	case kubectldebug.ProfileGeneral:
+		container.SecurityContext = &corev1.SecurityContext{
+			Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SYS_PTRACE"}},
+		}
+	case kubectldebug.ProfileRestricted:
+		container.SecurityContext = &corev1.SecurityContext{
+			RunAsNonRoot:             ptr.To(true),
+			AllowPrivilegeEscalation: ptr.To(false),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		}
+	case kubectldebug.ProfileNetadmin:
+		container.SecurityContext = &corev1.SecurityContext{
+			Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN", "NET_RAW"}},
+		}
+	case kubectldebug.ProfileSysadmin:
+		container.SecurityContext = &corev1.SecurityContext{Privileged: ptr.To(true)}
+	}
+
+	return container
+}
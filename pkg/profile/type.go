@@ -4,21 +4,39 @@ package profile
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type Profile struct {
-	ProfileName     string              `koanf:"name" yaml:"name" validate:"required"`
-	Profile         string              `koanf:"profile" yaml:"profile"`             // DEPRECATED: use ProfileSource instead
-	ProfileSource   ProfileSourceConfig `koanf:"profileSource" yaml:"profileSource"` // NEW: flexible profile source configuration
-	Image           string              `koanf:"image" yaml:"image" validate:"required"`
-	Namespace       string              `koanf:"namespace" yaml:"namespace" validate:"required"`
-	ImagePullPolicy corev1.PullPolicy   `koanf:"imagePullPolicy" yaml:"imagePullPolicy" validate:"required"`
-	TargetContainer string              `koanf:"targetContainer" yaml:"targetContainer" validate:"required"`
-	MatchLabels     map[string]string   `koanf:"matchLabels" yaml:"matchLabels" validate:"required"`
+	ProfileName      string              `koanf:"name" yaml:"name" validate:"required"`
+	Profile          string              `koanf:"profile" yaml:"profile"`             // DEPRECATED: use ProfileSource instead
+	ProfileSource    ProfileSourceConfig `koanf:"profileSource" yaml:"profileSource"` // NEW: flexible profile source configuration
+	Image            string              `koanf:"image" yaml:"image"`
+	Namespace        string              `koanf:"namespace" yaml:"namespace"`
+	Namespaces       NamespaceSelector   `koanf:"namespaces" yaml:"namespaces"` // namespaces searched for the target pods, instead of namespace
+	ImagePullPolicy  corev1.PullPolicy   `koanf:"imagePullPolicy" yaml:"imagePullPolicy"`
+	TargetContainer  string              `koanf:"targetContainer" yaml:"targetContainer"`
+	MatchLabels      map[string]string   `koanf:"matchLabels" yaml:"matchLabels"`
+	MatchExpressions []LabelRequirement  `koanf:"matchExpressions" yaml:"matchExpressions"` // label selector expressions, combined with matchLabels
+	FieldSelector    string              `koanf:"fieldSelector" yaml:"fieldSelector"`       // field selector of the target pods, e.g. status.phase=Running
+	Workload         string              `koanf:"workload" yaml:"workload"`                 // TYPE/NAME of the workload of the target pods, e.g. deployment/webapp
+	Contexts         []string            `koanf:"contexts" yaml:"contexts"`                 // kubeconfig contexts the profile is limited to
+	Clusters         []string            `koanf:"clusters" yaml:"clusters"`                 // kubeconfig clusters the profile is limited to
+	Record           RecordConfig        `koanf:"record" yaml:"record"`                     // recording of the debug sessions
+	Collect          []string            `koanf:"collect" yaml:"collect"`                   // files copied from the debug container at the end of a session
+	Limits           SessionLimits       `koanf:"limits" yaml:"limits"`                     // limits of the debug sessions
+	InheritEnvFrom   string              `koanf:"inheritEnvFrom" yaml:"inheritEnvFrom"`     // container of the target pod whose env and envFrom are copied
+	InheritEnvFilter EnvFilter           `koanf:"inheritEnvFilter" yaml:"inheritEnvFilter"` // filters the environment copied from inheritEnvFrom
 
 	// only used internally
 	builtInProfile bool
 	source         ProfileSource // resolved ProfileSource implementation
+	// resolved selector of the workload
+	workloadSelector *metav1.LabelSelector
+	// overrides of the spec for a single run
+	specOverrides SpecOverrides
+	// environment inherited from the target pod
+	inheritedEnv inheritedEnv
 }
 
 type Style struct {
@@ -29,9 +47,21 @@ type Style struct {
 }
 
 type CustomDebugProfile struct {
-	Profiles    []Profile `koanf:"profiles" yaml:"profiles"`
-	KubectlPath string    `koanf:"kubectlPath" yaml:"kubectlPath"`
-	Style       Style     `koanf:"style" yaml:"style"`
+	Profiles    []Profile       `koanf:"profiles" yaml:"profiles"`
+	KubectlPath string          `koanf:"kubectlPath" yaml:"kubectlPath"`
+	Style       Style           `koanf:"style" yaml:"style"`
+	Lint        LintConfig      `koanf:"lint" yaml:"lint"`
+	Policy      PolicyConfig    `koanf:"policy" yaml:"policy"`
+	Audit       AuditConfig     `koanf:"audit" yaml:"audit"`
+	Sessions    SessionsConfig  `koanf:"sessions" yaml:"sessions"`
+	Recording   RecordingConfig `koanf:"recording" yaml:"recording"`
+	Artifacts   ArtifactsConfig `koanf:"artifacts" yaml:"artifacts"`
+}
+
+// AuditConfig configures the audit log of debug sessions
+type AuditConfig struct {
+	// Path of the audit log, environment variables get expanded (default: ~/.kube-dpm/audit.log)
+	Path string `koanf:"path" yaml:"path"`
 }
 
 // global Profile configuration
@@ -45,6 +75,12 @@ func (p *Profile) SetBuiltInProfile(b bool) {
 	p.builtInProfile = b
 }
 
+// IsLegacy reports whether the profile sets the deprecated profile field, also if it is
+// ignored because profileSource is set.
+func (p *Profile) IsLegacy() bool {
+	return p.Profile != ""
+}
+
 func (p *Profile) GetSource() ProfileSource {
 	return p.source
 }
@@ -58,21 +94,21 @@ func (p *Profile) SetSource(s ProfileSource) {
 //
 //nolint:revive // ProfileSourceConfig is intentionally named this way for clarity
 type ProfileSourceConfig struct {
-	Type      string                 `koanf:"type" yaml:"type"`           // "file", "git", "configmap", "builtin"
-	Path      string                 `koanf:"path" yaml:"path"`           // for "file" type
-	Git       *GitSourceConfig       `koanf:"git" yaml:"git"`             // for "git" type
-	ConfigMap *ConfigMapSourceConfig `koanf:"configMap" yaml:"configMap"` // for "configmap" type
-	Name      string                 `koanf:"name" yaml:"name"`           // for "builtin" type (e.g., "netadmin")
+	Type      string                 `koanf:"type" yaml:"type" validate:"required"` // "file", "git", "configmap", "builtin"
+	Path      string                 `koanf:"path" yaml:"path"`                     // for "file" type
+	Git       *GitSourceConfig       `koanf:"git" yaml:"git"`                       // for "git" type
+	ConfigMap *ConfigMapSourceConfig `koanf:"configMap" yaml:"configMap"`           // for "configmap" type
+	Name      string                 `koanf:"name" yaml:"name"`                     // for "builtin" type (e.g., "netadmin")
 }
 
 // GitSourceConfig defines configuration for Git repository profile sources.
 type GitSourceConfig struct {
-	URL  string `koanf:"url" yaml:"url"`   // Git repository URL (e.g., "https://github.com/org/repo")
-	Ref  string `koanf:"ref" yaml:"ref"`   // Branch, tag, or commit (default: "main")
-	Path string `koanf:"path" yaml:"path"` // Path to profile.json within the repository
+	URL  string `koanf:"url" yaml:"url" validate:"required"`   // Git repository URL (e.g., "https://github.com/org/repo")
+	Ref  string `koanf:"ref" yaml:"ref"`                       // Branch or tag (default: "main")
+	Path string `koanf:"path" yaml:"path" validate:"required"` // Path to profile.json within the repository
 }
 
 // ConfigMapSourceConfig defines configuration for Kubernetes ConfigMap profile sources.
 type ConfigMapSourceConfig struct {
-	Name string `koanf:"name" yaml:"name"` // ConfigMap name (namespace is taken from Profile.Namespace)
+	Name string `koanf:"name" yaml:"name" validate:"required"` // ConfigMap name (namespace is taken from Profile.Namespace)
 }
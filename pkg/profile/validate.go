@@ -11,6 +11,8 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -54,13 +56,40 @@ func ValidateKubectlPath() error {
 	return nil
 }
 
+// Concurrency is the maximum number of profiles validated at the same time.
+// Validating a profile fetches its source, e.g. clones a git repository.
+var Concurrency = 8
+
+// Debug enables debug output of the validation, e.g. the time needed per profile
+var Debug bool
+
+// ValidateAllProfiles validates all profiles and instantiates their ProfileSource.
+// Findings about the spec content (e.g. a git source which can't be cloned) are only
+// logged, so that a single broken source doesn't block the other profiles.
+// Use CollectFindings to get all findings.
 func ValidateAllProfiles(ctx context.Context) error {
+	report := CollectFindings(ctx)
+	logSpecFindings(report)
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("validation cancelled: %w", err)
+	}
+
+	return report.Err()
+}
+
+// CollectFindings validates all profiles, instantiates their ProfileSource and
+// returns all findings instead of stopping at the first error.
+// Profiles are validated concurrently by at most Concurrency workers.
+func CollectFindings(ctx context.Context) *Report {
+	report := &Report{}
+
 	// sort profiles by name
 	SortProfiles()
 
 	compactProfiles := slices.CompactFunc(Config.Profiles, func(a, b Profile) bool {
 		if strings.EqualFold(a.ProfileName, b.ProfileName) {
-			log.Printf("duplicate profile name %s found - keep the one with profile file %s\n", a.ProfileName, a.Profile)
+			report.Add(SeverityWarning, a.ProfileName, "name", "duplicate profile name - only the first profile with this name is used")
 			return true
 		}
 		return false
@@ -69,21 +98,70 @@ func ValidateAllProfiles(ctx context.Context) error {
 	// update Config.Profiles with compacted profiles
 	Config.Profiles = compactProfiles
 
+	type result struct {
+		profile  Profile
+		report   Report
+		duration time.Duration
+	}
+
+	// every worker validates a copy of its profile and writes only its own result,
+	// Config.Profiles is only updated after all workers are done
+	results := make([]result, len(Config.Profiles))
+	workers := make(chan struct{}, max(Concurrency, 1))
+
+	var wg sync.WaitGroup
+
 	for idx, p := range Config.Profiles {
+		r := &results[idx]
+		r.profile = p
+
 		switch {
 		case p.ProfileName == "":
-			Config.Profiles = slices.Delete(Config.Profiles, idx, idx)
-			return fmt.Errorf("profile at index %d is missing a custom profile name", idx)
+			r.report.Add(SeverityError, "", fmt.Sprintf("profiles[%d].name", idx), "profile is missing a custom profile name")
+			continue
 		case p.ProfileSource.Type == "" && p.Profile == "":
-			Config.Profiles = slices.Delete(Config.Profiles, idx, idx)
-			return fmt.Errorf("profile %q is missing both profileSource and profile (legacy) configuration", p.ProfileName)
+			r.report.Profiles = []string{p.ProfileName}
+			r.report.Add(SeverityError, p.ProfileName, "profileSource", "profile is missing both profileSource and profile (legacy) configuration")
+			continue
 		}
 
-		if err := ValidateProfile(ctx, p.ProfileName); err != nil {
-			return fmt.Errorf("validate profile %q: %w", p.ProfileName, err)
+		r.report.Profiles = []string{p.ProfileName}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case workers <- struct{}{}:
+				defer func() { <-workers }()
+			case <-ctx.Done():
+				r.report.Add(SeverityError, p.ProfileName, "", "validation cancelled: %v", ctx.Err())
+				return
+			}
+
+			start := time.Now()
+			validateProfile(ctx, &r.profile, &r.report)
+			r.duration = time.Since(start)
+		}()
+	}
+
+	wg.Wait()
+
+	for idx, r := range results {
+		Config.Profiles[idx] = r.profile
+		report.Profiles = append(report.Profiles, r.report.Profiles...)
+		report.Findings = append(report.Findings, r.report.Findings...)
+
+		if Debug && r.duration > 0 {
+			log.Printf("profile %s validated in %s\n", r.profile.ProfileName, r.duration.Round(time.Millisecond))
 		}
 	}
-	return nil
+
+	validatePolicy(report)
+	validateRecording(report)
+	validateLimits("sessions.limits", "", Config.Sessions.Limits, Config.Sessions.Limits.Active(), report)
+
+	return report
 }
 
 // ValidateProfile validates a single profile and instantiates its ProfileSource
@@ -92,55 +170,104 @@ func ValidateProfile(ctx context.Context, profileName string) error {
 	if err != nil {
 		return err
 	}
-	profile := &Config.Profiles[idx]
+
+	report := &Report{}
+	validateProfile(ctx, &Config.Profiles[idx], report)
+	logSpecFindings(report)
+
+	return report.Err()
+}
+
+func validateProfile(ctx context.Context, p *Profile, report *Report) {
+	validateImage(p, report)
+	validateRecord(p, report)
+	validateCollect(p, report)
+	validateWorkload(p, report)
+	validateSelector(p, report)
+	validateNamespaces(p, report)
+	validateInheritEnv(p, report)
+	validateLimits("limits", p.ProfileName, p.Limits, p.SessionLimits().Active(), report)
 
 	// Check if using new ProfileSource config or legacy Profile field
-	if profile.ProfileSource.Type != "" {
+	if p.ProfileSource.Type != "" {
+		if p.IsLegacy() {
+			report.Add(SeverityWarning, p.ProfileName, "profile", "the deprecated profile field is ignored because profileSource is set - run 'kubectl dpm config migrate' to remove it")
+		}
+
 		// New ProfileSource configuration
-		return validateAndInstantiateProfileSource(ctx, profile, nil)
+		validateAndInstantiateProfileSource(ctx, p, nil, report)
+		return
 	}
 
 	// Legacy Profile field - handle for backward compatibility
-	if profile.Profile == "" {
-		return fmt.Errorf("profile %q is missing both profileSource and profile fields", profileName)
+	if !p.IsLegacy() {
+		report.Add(SeverityError, p.ProfileName, "profileSource", "profile is missing both profileSource and profile fields")
+		return
 	}
 
-	return validateLegacyProfile(idx)
+	report.Add(SeverityWarning, p.ProfileName, "profile", "the profile field is deprecated - run 'kubectl dpm config migrate'")
+	validateLegacyProfile(p, report)
+}
+
+// validateImage reports profiles without image, the image is optional as it can be set with run --image
+func validateImage(p *Profile, report *Report) {
+	if p.Image == "" {
+		report.Add(SeverityInfo, p.ProfileName, "image", "no image configured - run needs --image")
+	}
+}
+
+// logSpecFindings logs the findings about the spec content
+func logSpecFindings(report *Report) {
+	for _, f := range report.Findings {
+		if f.Field == FieldSpec {
+			log.Printf("profile %s validation %s: %s\n", f.Profile, f.Severity, f.Message)
+		}
+	}
 }
 
 // validateAndInstantiateProfileSource creates the appropriate ProfileSource implementation
 // based on the ProfileSourceConfig. The k8sClient parameter is optional and only needed
 // for ConfigMap sources.
-func validateAndInstantiateProfileSource(ctx context.Context, p *Profile, k8sClient corev1client.CoreV1Interface) error {
+func validateAndInstantiateProfileSource(ctx context.Context, p *Profile, k8sClient corev1client.CoreV1Interface, report *Report) {
 	var source ProfileSource
 	var err error
 
+	fail := func(field, format string, args ...any) {
+		report.Add(SeverityError, p.ProfileName, field, format, args...)
+	}
+
 	switch p.ProfileSource.Type {
 	case SourceTypeFile:
 		if p.ProfileSource.Path == "" {
-			return fmt.Errorf("file profile source requires 'path' field")
+			fail("profileSource.path", "file profile source requires 'path' field")
+			return
 		}
 		source = NewFileProfileSource(p.ProfileSource.Path)
 
 	case SourceTypeBuiltIn:
 		if p.ProfileSource.Name == "" {
-			return fmt.Errorf("builtin profile source requires 'name' field")
+			fail("profileSource.name", "builtin profile source requires 'name' field")
+			return
 		}
 		source, err = NewBuiltInProfileSource(p.ProfileSource.Name)
 		if err != nil {
-			return fmt.Errorf("create builtin profile source: %w", err)
+			fail("profileSource.name", "create builtin profile source: %v", err)
+			return
 		}
 		p.SetBuiltInProfile(true)
 
 	case SourceTypeGit:
 		if p.ProfileSource.Git == nil {
-			return fmt.Errorf("git profile source requires 'git' configuration")
+			fail("profileSource.git", "git profile source requires 'git' configuration")
+			return
 		}
 		if p.ProfileSource.Git.URL == "" {
-			return fmt.Errorf("git profile source requires 'git.url' field")
+			fail("profileSource.git.url", "git profile source requires 'git.url' field")
+			return
 		}
 		if p.ProfileSource.Git.Path == "" {
-			return fmt.Errorf("git profile source requires 'git.path' field")
+			fail("profileSource.git.path", "git profile source requires 'git.path' field")
+			return
 		}
 		source = NewGitProfileSource(
 			p.ProfileSource.Git.URL,
@@ -150,13 +277,16 @@ func validateAndInstantiateProfileSource(ctx context.Context, p *Profile, k8sCli
 
 	case SourceTypeConfigMap:
 		if p.ProfileSource.ConfigMap == nil {
-			return fmt.Errorf("configmap profile source requires 'configMap' configuration")
+			fail("profileSource.configMap", "configmap profile source requires 'configMap' configuration")
+			return
 		}
 		if p.ProfileSource.ConfigMap.Name == "" {
-			return fmt.Errorf("configmap profile source requires 'configMap.name' field")
+			fail("profileSource.configMap.name", "configmap profile source requires 'configMap.name' field")
+			return
 		}
 		if p.Namespace == "" {
-			return fmt.Errorf("configmap profile source requires 'namespace' field to locate the ConfigMap")
+			fail("namespace", "configmap profile source requires 'namespace' field to locate the ConfigMap")
+			return
 		}
 		// For now, we'll create the source without a client during validation
 		// The actual client will be injected later when needed
@@ -165,12 +295,13 @@ func validateAndInstantiateProfileSource(ctx context.Context, p *Profile, k8sCli
 		} else {
 			// Validation-only mode - we can't actually fetch the ConfigMap yet
 			// Just verify the configuration is complete
-			log.Printf("configmap profile source %q will be validated at runtime\n", p.ProfileName)
-			return nil
+			report.Add(SeverityInfo, p.ProfileName, FieldSpec, "configmap profile source will be validated at runtime")
+			return
 		}
 
 	default:
-		return fmt.Errorf("unknown profile source type: %q (valid types: file, git, configmap, builtin)", p.ProfileSource.Type)
+		fail("profileSource.type", "unknown profile source type: %q (valid types: file, git, configmap, builtin)", p.ProfileSource.Type)
+		return
 	}
 
 	// Store the source implementation
@@ -178,45 +309,43 @@ func validateAndInstantiateProfileSource(ctx context.Context, p *Profile, k8sCli
 
 	// Validate by fetching the spec (except for ConfigMap during initial validation)
 	if p.ProfileSource.Type != SourceTypeConfigMap {
-		_, err = source.GetSpec(ctx)
+		spec, err := source.GetSpec(ctx)
 		if err != nil {
-			log.Printf("profile %s validation warning: %s\n", p.ProfileName, err.Error())
+			fail(FieldSpec, "%v", err)
+			return
 		}
-	}
 
-	return nil
+		// built-in profiles don't have a spec
+		if spec != nil {
+			if err := json.Unmarshal(spec, &corev1.Container{}); err != nil {
+				fail(FieldSpec, "parse profile spec: %v", err)
+			}
+		}
+	}
 }
 
 // validateLegacyProfile validates profiles using the legacy 'profile' field
-func validateLegacyProfile(idx int) error {
-	switch Config.Profiles[idx].Profile {
+func validateLegacyProfile(p *Profile, report *Report) {
+	switch p.Profile {
 	// SA1019: ProfileLegacy is deprecated: legacyProfile is planned to be removed in v1.39
 	// nolint:staticcheck
 	case kubectldebug.ProfileLegacy:
-		Config.Profiles[idx].SetBuiltInProfile(true)
-		return nil
+		p.SetBuiltInProfile(true)
 	case kubectldebug.ProfileGeneral:
-		Config.Profiles[idx].SetBuiltInProfile(true)
-		return nil
+		p.SetBuiltInProfile(true)
 	case kubectldebug.ProfileBaseline:
-		Config.Profiles[idx].SetBuiltInProfile(true)
-		return nil
+		p.SetBuiltInProfile(true)
 	case kubectldebug.ProfileRestricted:
-		Config.Profiles[idx].SetBuiltInProfile(true)
-		return nil
+		p.SetBuiltInProfile(true)
 	case kubectldebug.ProfileNetadmin:
-		Config.Profiles[idx].SetBuiltInProfile(true)
-		return nil
+		p.SetBuiltInProfile(true)
 	case kubectldebug.ProfileSysadmin:
-		Config.Profiles[idx].SetBuiltInProfile(true)
-		return nil
+		p.SetBuiltInProfile(true)
 	default:
-		if err := validatePodSpec(Config.Profiles[idx].Profile); err != nil {
-			log.Printf("profile %s is invalid: %s\n", Config.Profiles[idx].Profile, err.Error())
+		if err := validatePodSpec(p.Profile); err != nil {
+			report.Add(SeverityError, p.ProfileName, FieldSpec, "%v", err)
 		}
 	}
-
-	return nil
 }
 
 // InteractiveProfiles returns all profiles that can be used from
@@ -249,7 +378,7 @@ func namespaceIsMissing(profileName string) bool {
 	if err != nil {
 		return true
 	}
-	return Config.Profiles[idx].Namespace == ""
+	return !Config.Profiles[idx].HasNamespace()
 }
 
 func labelSelectorIsMissing(profileName string) bool {
@@ -257,7 +386,7 @@ func labelSelectorIsMissing(profileName string) bool {
 	if err != nil {
 		return true
 	}
-	return Config.Profiles[idx].MatchLabels == nil
+	return !Config.Profiles[idx].HasPodSelector()
 }
 
 func imageIsMissing(profileName string) bool {
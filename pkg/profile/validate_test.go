@@ -302,3 +302,127 @@ func TestCompleteProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectFindings(t *testing.T) {
+	Config = CustomDebugProfile{
+		Profiles: []Profile{
+			{
+				ProfileName:   "both",
+				Image:         "busybox:1.37",
+				Profile:       "sysadmin",
+				ProfileSource: ProfileSourceConfig{Type: SourceTypeBuiltIn, Name: "netadmin"},
+			},
+			{
+				ProfileName:   "builtin",
+				Image:         "busybox:1.37",
+				ProfileSource: ProfileSourceConfig{Type: SourceTypeBuiltIn, Name: "netadmin"},
+			},
+			{
+				ProfileName:   "missing-file",
+				Image:         "busybox:1.37",
+				ProfileSource: ProfileSourceConfig{Type: SourceTypeFile, Path: "test_data/does-not-exist.json"},
+			},
+			{
+				ProfileName:   "missing-git-url",
+				Image:         "busybox:1.37",
+				ProfileSource: ProfileSourceConfig{Type: SourceTypeGit, Git: &GitSourceConfig{Path: "profile.json"}},
+			},
+			{
+				ProfileName: "legacy",
+				Profile:     "netadmin",
+			},
+			{
+				Profile: "sysadmin",
+			},
+		},
+	}
+
+	report := CollectFindings(context.Background())
+
+	want := []Finding{
+		{Severity: SeverityError, Field: "profiles[0].name", Message: "profile is missing a custom profile name"},
+		{Severity: SeverityWarning, Profile: "both", Field: "profile", Message: "the deprecated profile field is ignored because profileSource is set - run 'kubectl dpm config migrate' to remove it"},
+		{Severity: SeverityInfo, Profile: "legacy", Field: "image", Message: "no image configured - run needs --image"},
+		{Severity: SeverityWarning, Profile: "legacy", Field: "profile", Message: "the profile field is deprecated - run 'kubectl dpm config migrate'"},
+		{Severity: SeverityError, Profile: "missing-file", Field: FieldSpec},
+		{Severity: SeverityError, Profile: "missing-git-url", Field: "profileSource.git.url", Message: "git profile source requires 'git.url' field"},
+	}
+
+	if len(report.Findings) != len(want) {
+		t.Fatalf("CollectFindings() = %+v, want %d findings", report.Findings, len(want))
+	}
+
+	for i, f := range report.Findings {
+		// the message of a fetch failure depends on the operating system
+		if want[i].Message == "" {
+			f.Message = ""
+		}
+		if f != want[i] {
+			t.Errorf("CollectFindings() finding %d = %+v, want %+v", i, f, want[i])
+		}
+	}
+
+	wantProfiles := []string{"both", "builtin", "legacy", "missing-file", "missing-git-url"}
+	if !reflect.DeepEqual(report.Profiles, wantProfiles) {
+		t.Errorf("CollectFindings() profiles = %v, want %v", report.Profiles, wantProfiles)
+	}
+
+	if !report.Failed(false) {
+		t.Errorf("CollectFindings() report should fail")
+	}
+}
+
+func TestCollectFindings_Concurrent(t *testing.T) {
+	repoPath := setupTestGitRepo(t, testValidProfile, "profile.json")
+
+	CleanupGitClones()
+	t.Cleanup(CleanupGitClones)
+
+	Config = CustomDebugProfile{}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		Config.Profiles = append(Config.Profiles, Profile{
+			ProfileName: name,
+			Image:       "busybox:1.37",
+			ProfileSource: ProfileSourceConfig{
+				Type: SourceTypeGit,
+				Git:  &GitSourceConfig{URL: repoPath, Path: "profile.json"},
+			},
+		})
+	}
+
+	originalConcurrency := Concurrency
+	Concurrency = 2
+	t.Cleanup(func() { Concurrency = originalConcurrency })
+
+	report := CollectFindings(context.Background())
+
+	if len(report.Findings) != 0 {
+		t.Errorf("CollectFindings() findings = %+v, want none", report.Findings)
+	}
+
+	for _, p := range Config.Profiles {
+		if p.GetSource() == nil {
+			t.Errorf("profile %q has no instantiated source", p.ProfileName)
+		}
+	}
+
+	// all profiles share the same repository and ref
+	if len(gitClones.clones) != 1 {
+		t.Errorf("repository cloned %d times, want once", len(gitClones.clones))
+	}
+}
+
+func TestValidateAllProfiles_Cancelled(t *testing.T) {
+	Config = CustomDebugProfile{
+		Profiles: []Profile{
+			{ProfileName: "netadmin", ProfileSource: ProfileSourceConfig{Type: SourceTypeBuiltIn, Name: "netadmin"}},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := ValidateAllProfiles(ctx); err == nil {
+		t.Errorf("ValidateAllProfiles() with cancelled context error = nil, want error")
+	}
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	WorkloadKindPod         = "pod"
+	WorkloadKindDeployment  = "deployment"
+	WorkloadKindStatefulSet = "statefulset"
+	WorkloadKindDaemonSet   = "daemonset"
+	WorkloadKindReplicaSet  = "replicaset"
+	WorkloadKindJob         = "job"
+)
+
+// workloadKinds maps the accepted resource names (incl. plural and short names) to their kind
+var workloadKinds = map[string]string{
+	"pod": WorkloadKindPod, "pods": WorkloadKindPod, "po": WorkloadKindPod,
+	"deployment": WorkloadKindDeployment, "deployments": WorkloadKindDeployment, "deploy": WorkloadKindDeployment,
+	"statefulset": WorkloadKindStatefulSet, "statefulsets": WorkloadKindStatefulSet, "sts": WorkloadKindStatefulSet,
+	"daemonset": WorkloadKindDaemonSet, "daemonsets": WorkloadKindDaemonSet, "ds": WorkloadKindDaemonSet,
+	"replicaset": WorkloadKindReplicaSet, "replicasets": WorkloadKindReplicaSet, "rs": WorkloadKindReplicaSet,
+	"job": WorkloadKindJob, "jobs": WorkloadKindJob,
+}
+
+// WorkloadRef is a parsed TYPE/NAME argument like "deployment/webapp"
+type WorkloadRef struct {
+	Kind string
+	Name string
+}
+
+func (r WorkloadRef) String() string {
+	return r.Kind + "/" + r.Name
+}
+
+// WorkloadKind returns the kind of a resource name like "deploy" or "Deployment"
+func WorkloadKind(resource string) (string, bool) {
+	kind, ok := workloadKinds[strings.ToLower(resource)]
+	return kind, ok
+}
+
+// ParseWorkloadRef parses TYPE/NAME, a plain NAME refers to a pod
+func ParseWorkloadRef(arg string) (WorkloadRef, error) {
+	resource, name, found := strings.Cut(arg, "/")
+	if !found {
+		resource, name = WorkloadKindPod, arg
+	}
+
+	kind, ok := WorkloadKind(resource)
+	if !ok {
+		return WorkloadRef{}, fmt.Errorf("unsupported resource type %q (supported: pod, deployment, statefulset, daemonset, replicaset, job)", resource)
+	}
+
+	if name == "" {
+		return WorkloadRef{}, fmt.Errorf("missing name in %q", arg)
+	}
+
+	return WorkloadRef{Kind: kind, Name: name}, nil
+}
+
+// SetWorkloadSelector sets the resolved selector of the workload of the profile
+func (p *Profile) SetWorkloadSelector(selector *metav1.LabelSelector) {
+	p.workloadSelector = selector
+}
+
+// HasPodSelector reports whether the target pods can be selected by the workload,
+// the label selector or the field selector of the profile
+func (p *Profile) HasPodSelector() bool {
+	return p.Workload != "" || p.hasLabelSelector() || p.FieldSelector != ""
+}
+
+// PodSelector returns the label selector of the target pods, the selector of the workload
+// combined with the matchLabels and matchExpressions. The workload selector must be set before.
+func (p *Profile) PodSelector() (labels.Selector, error) {
+	if p.Workload != "" && p.workloadSelector == nil {
+		return nil, fmt.Errorf("workload %s of profile %q isn't resolved", p.Workload, p.ProfileName)
+	}
+
+	selector := labels.Everything()
+	if p.workloadSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(p.workloadSelector); err != nil {
+			return nil, fmt.Errorf("selector of workload %s: %w", p.Workload, err)
+		}
+	}
+
+	requirements, err := metav1.LabelSelectorAsSelector(p.labelSelector())
+	if err != nil {
+		return nil, fmt.Errorf("label selector: %w", err)
+	}
+	reqs, _ := requirements.Requirements()
+
+	return selector.Add(reqs...), nil
+}
+
+func validateWorkload(p *Profile, report *Report) {
+	if p.Workload == "" {
+		return
+	}
+
+	ref, err := ParseWorkloadRef(p.Workload)
+	switch {
+	case err != nil:
+		report.Add(SeverityError, p.ProfileName, "workload", "%v", err)
+	case !strings.Contains(p.Workload, "/") || ref.Kind == WorkloadKindPod:
+		report.Add(SeverityError, p.ProfileName, "workload", "workload %q must be TYPE/NAME of a deployment, statefulset, daemonset, replicaset or job", p.Workload)
+	}
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseWorkloadRef(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    WorkloadRef
+		wantErr bool
+	}{
+		{arg: "webapp-x2x9q", want: WorkloadRef{Kind: WorkloadKindPod, Name: "webapp-x2x9q"}},
+		{arg: "pod/webapp-x2x9q", want: WorkloadRef{Kind: WorkloadKindPod, Name: "webapp-x2x9q"}},
+		{arg: "deploy/webapp", want: WorkloadRef{Kind: WorkloadKindDeployment, Name: "webapp"}},
+		{arg: "StatefulSet/db", want: WorkloadRef{Kind: WorkloadKindStatefulSet, Name: "db"}},
+		{arg: "service/webapp", wantErr: true},
+		{arg: "deployment/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := ParseWorkloadRef(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWorkloadRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWorkloadRef() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodSelector(t *testing.T) {
+	tests := []struct {
+		name             string
+		profile          Profile
+		workloadSelector *metav1.LabelSelector
+		want             string
+		wantErr          bool
+	}{
+		{
+			name:    "matchLabels",
+			profile: Profile{MatchLabels: map[string]string{"app": "webapp"}},
+			want:    "app=webapp",
+		},
+		{
+			name:             "workload",
+			profile:          Profile{Workload: "deployment/webapp"},
+			workloadSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"run": "webapp"}},
+			want:             "run=webapp",
+		},
+		{
+			name:             "workload and matchLabels",
+			profile:          Profile{Workload: "deployment/webapp", MatchLabels: map[string]string{"track": "canary"}},
+			workloadSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"run": "webapp"}},
+			want:             "run=webapp,track=canary",
+		},
+		{
+			name:    "unresolved workload",
+			profile: Profile{Workload: "deployment/webapp"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.profile
+			p.SetWorkloadSelector(tt.workloadSelector)
+
+			got, err := p.PodSelector()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PodSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("PodSelector() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWorkload(t *testing.T) {
+	tests := []struct {
+		workload string
+		wantErr  bool
+	}{
+		{workload: ""},
+		{workload: "deployment/webapp"},
+		{workload: "sts/db"},
+		{workload: "webapp", wantErr: true},
+		{workload: "pod/webapp", wantErr: true},
+		{workload: "service/webapp", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.workload, func(t *testing.T) {
+			report := &Report{}
+			validateWorkload(&Profile{ProfileName: "test", Workload: tt.workload}, report)
+
+			if got := report.Count(SeverityError) > 0; got != tt.wantErr {
+				t.Errorf("validateWorkload(%q) errors = %v, want %v", tt.workload, report.Findings, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: MIT
+
+// Package recording records terminal sessions in the asciicast v2 format
+// (https://docs.asciinema.org/manual/asciicast/v2/) and plays them back.
+package recording
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	EventOutput = "o"
+	EventInput  = "i"
+	EventResize = "r"
+
+	// Redacted replaces redacted data
+	Redacted = "[REDACTED]"
+)
+
+// Header is the first line of an asciicast v2 recording
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is a single event of a recording
+type Event struct {
+	Time float64 // seconds since the start of the recording
+	Type string
+	Data string
+}
+
+func (e *Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{e.Time, e.Type, e.Data})
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("event has %d elements, want 3", len(raw))
+	}
+	if err := json.Unmarshal(raw[0], &e.Time); err != nil {
+		return fmt.Errorf("event time: %w", err)
+	}
+	if err := json.Unmarshal(raw[1], &e.Type); err != nil {
+		return fmt.Errorf("event type: %w", err)
+	}
+	if err := json.Unmarshal(raw[2], &e.Data); err != nil {
+		return fmt.Errorf("event data: %w", err)
+	}
+	return nil
+}
+
+// Options configures a Recorder
+type Options struct {
+	// Input records the input (keystrokes), it's not recorded by default as it may contain secrets
+	Input bool
+	// Redact replaces all matches of the patterns in the recorded data with Redacted.
+	// Data is matched per read, a match split between two reads isn't redacted.
+	Redact []*regexp.Regexp
+}
+
+// Recorder writes an asciicast v2 recording, it's safe for concurrent use
+type Recorder struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	start   time.Time
+	options Options
+	err     error
+	now     func() time.Time
+}
+
+// NewRecorder writes the header and returns a Recorder for the events
+func NewRecorder(w io.Writer, header Header, options Options) (*Recorder, error) {
+	r := &Recorder{enc: json.NewEncoder(w), options: options, now: time.Now}
+	r.start = r.now()
+
+	header.Version = 2
+	if header.Timestamp == 0 {
+		header.Timestamp = r.start.Unix()
+	}
+
+	if err := r.enc.Encode(&header); err != nil {
+		return nil, fmt.Errorf("write recording header: %w", err)
+	}
+
+	return r, nil
+}
+
+// Record records an event, write errors are returned by Err
+func (r *Recorder) Record(eventType, data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	for _, re := range r.options.Redact {
+		data = re.ReplaceAllString(data, Redacted)
+	}
+
+	r.err = r.enc.Encode(&Event{Time: r.now().Sub(r.start).Seconds(), Type: eventType, Data: data})
+}
+
+// Resize records a new terminal size
+func (r *Recorder) Resize(width, height int) {
+	r.Record(EventResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// Err returns the first error writing the recording
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Output returns a writer which records everything written to w
+func (r *Recorder) Output(w io.Writer) io.Writer {
+	return &recordingWriter{w: w, record: func(p []byte) { r.Record(EventOutput, string(p)) }}
+}
+
+// Input returns a reader which records everything read from in, if Options.Input is set
+func (r *Recorder) Input(in io.Reader) io.Reader {
+	if !r.options.Input || in == nil {
+		return in
+	}
+	return io.TeeReader(in, &recordingWriter{w: io.Discard, record: func(p []byte) { r.Record(EventInput, string(p)) }})
+}
+
+type recordingWriter struct {
+	w      io.Writer
+	record func(p []byte)
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	n, err := rw.w.Write(p)
+	if n > 0 {
+		rw.record(p[:n])
+	}
+	return n, err
+}
+
+// Read reads the header and all events of a recording
+func Read(r io.Reader) (*Header, []Event, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, fmt.Errorf("read recording: %w", err)
+		}
+		return nil, nil, errors.New("recording is empty")
+	}
+
+	header := &Header{}
+	if err := json.Unmarshal(scanner.Bytes(), header); err != nil {
+		return nil, nil, fmt.Errorf("parse recording header: %w", err)
+	}
+	if header.Version != 2 {
+		return nil, nil, fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+
+	var events []Event
+	for line := 2; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, nil, fmt.Errorf("parse recording line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read recording: %w", err)
+	}
+
+	return header, events, nil
+}
+
+// ReplayOptions configures Replay
+type ReplayOptions struct {
+	// Speed is the playback speed, e.g. 2 plays twice as fast
+	Speed float64
+	// IdleLimit limits the pause between two events, 0 keeps the recorded pauses
+	IdleLimit time.Duration
+}
+
+// Replay writes the output events to out with their recorded timing
+func Replay(ctx context.Context, out io.Writer, events []Event, options ReplayOptions) error {
+	speed := options.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+
+	last := 0.0
+	for _, e := range events {
+		if e.Type != EventOutput {
+			continue
+		}
+
+		pause := time.Duration((e.Time - last) / speed * float64(time.Second))
+		if options.IdleLimit > 0 {
+			pause = min(pause, options.IdleLimit)
+		}
+		last = e.Time
+
+		if pause > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(pause):
+			}
+		}
+
+		if _, err := io.WriteString(out, e.Data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
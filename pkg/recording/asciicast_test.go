@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+
+package recording
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		want    string
+	}{
+		{
+			name: "output only",
+			want: `{"version":2,"width":120,"height":40,"timestamp":1748779200,"title":"test"}
+[0.5,"o","$ "]
+[1.5,"o","export TOKEN=s3cr3t\r\n"]
+[2.5,"r","100x30"]
+`,
+		},
+		{
+			name:    "input and redaction",
+			options: Options{Input: true, Redact: []*regexp.Regexp{regexp.MustCompile(`TOKEN=\S+`)}},
+			want: `{"version":2,"width":120,"height":40,"timestamp":1748779200,"title":"test"}
+[0.5,"o","$ "]
+[1,"i","export [REDACTED]\r"]
+[1.5,"o","export [REDACTED]\r\n"]
+[2.5,"r","100x30"]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+			clock := start
+			r, err := NewRecorder(&buf, Header{Width: 120, Height: 40, Timestamp: start.Unix(), Title: "test"}, tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.start = start
+			r.now = func() time.Time { return clock }
+
+			out := r.Output(io.Discard)
+			in := r.Input(strings.NewReader("export TOKEN=s3cr3t\r"))
+
+			clock = start.Add(500 * time.Millisecond)
+			io.WriteString(out, "$ ")
+			clock = start.Add(time.Second)
+			io.ReadAll(in)
+			clock = start.Add(1500 * time.Millisecond)
+			io.WriteString(out, "export TOKEN=s3cr3t\r\n")
+			clock = start.Add(2500 * time.Millisecond)
+			r.Resize(100, 30)
+
+			if err := r.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("recording =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+
+			header, events, err := Read(&buf)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if header.Version != 2 || header.Width != 120 || header.Height != 40 {
+				t.Errorf("Read() header = %+v", header)
+			}
+			if got := len(events); got != strings.Count(tt.want, "\n")-1 {
+				t.Errorf("Read() got %d events", got)
+			}
+		})
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "empty", data: "", wantErr: "recording is empty"},
+		{name: "version", data: `{"version":1}`, wantErr: "unsupported asciicast version 1"},
+		{name: "event", data: "{\"version\":2}\n[0.1,\"o\"]\n", wantErr: "parse recording line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Read(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Read() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	events := []Event{
+		{Time: 0.01, Type: EventOutput, Data: "$ "},
+		{Time: 0.02, Type: EventInput, Data: "ls\r"},
+		{Time: 60, Type: EventOutput, Data: "ls\r\n"},
+		{Time: 60, Type: EventResize, Data: "100x30"},
+	}
+
+	var out bytes.Buffer
+	start := time.Now()
+	if err := Replay(context.Background(), &out, events, ReplayOptions{Speed: 2, IdleLimit: 10 * time.Millisecond}); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	if out.String() != "$ ls\r\n" {
+		t.Errorf("Replay() = %q", out.String())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Replay() ignored the idle limit, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Replay(ctx, io.Discard, events, ReplayOptions{}); err == nil {
+		t.Errorf("Replay() with a cancelled context succeeded")
+	}
+}
@@ -3,9 +3,6 @@
 package table
 
 import (
-	"encoding/json"
-	"log"
-
 	bubbletable "github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/lipgloss"
 
@@ -21,7 +18,7 @@ func GenerateTable(profiles []profile.Profile, wide bool) bubbletable.Model {
 	longestProfile := 0
 	longestImage := 0
 	longestNamespace := 0
-	longestMatchLabels := 0
+	longestSelector := 0
 
 	// get all profiles and get string length for column width
 	for _, p := range profiles {
@@ -37,17 +34,14 @@ func GenerateTable(profiles []profile.Profile, wide bool) bubbletable.Model {
 			longestImage = len(p.Image)
 		}
 
-		if len(p.Namespace) > longestNamespace {
-			longestNamespace = len(p.Namespace)
+		namespace := p.FormatNamespace()
+		if len(namespace) > longestNamespace {
+			longestNamespace = len(namespace)
 		}
 
-		jsonString, err := json.Marshal(p.MatchLabels)
-		if err != nil {
-			log.Printf("error marshalling matchLabels: %v", err)
-			continue
-		}
-		if len(string(jsonString)) > longestMatchLabels {
-			longestMatchLabels = len(string(jsonString))
+		selector := p.FormatSelector()
+		if len(selector) > longestSelector {
+			longestSelector = len(selector)
 		}
 
 		if wide {
@@ -55,8 +49,8 @@ func GenerateTable(profiles []profile.Profile, wide bool) bubbletable.Model {
 				p.ProfileName,
 				p.Profile,
 				p.Image,
-				p.Namespace,
-				string(jsonString),
+				namespace,
+				selector,
 			})
 		} else {
 			rows = append(rows, bubbletable.Row{
@@ -75,7 +69,7 @@ func GenerateTable(profiles []profile.Profile, wide bool) bubbletable.Model {
 	if wide {
 		columns = append(columns, bubbletable.Column{Title: "Image", Width: longestImage})
 		columns = append(columns, bubbletable.Column{Title: "Namespace", Width: longestNamespace})
-		columns = append(columns, bubbletable.Column{Title: "MatchLabels", Width: longestMatchLabels})
+		columns = append(columns, bubbletable.Column{Title: "Selector", Width: longestSelector})
 	}
 
 	t := bubbletable.New(
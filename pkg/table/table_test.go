@@ -63,8 +63,29 @@ func TestGenerateTable(t *testing.T) {
 			},
 			wide: true,
 			expected: []bubbletable.Row{
-				{"profile1", "test_data/profile1.json", "busybox", "default", `{"app":"test"}`},
-				{"profile2", "test_data/profile2.json", "nginx", "kube-system", `{"app":"nginx"}`},
+				{"profile1", "test_data/profile1.json", "busybox", "default", "app=test"},
+				{"profile2", "test_data/profile2.json", "nginx", "kube-system", "app=nginx"},
+			},
+		},
+		{
+			name: "full selector",
+			profiles: []profile.Profile{
+				{
+					ProfileName: "canary",
+					Profile:     "test_data/profile1.json",
+					Image:       "busybox",
+					Namespace:   "default",
+					Workload:    "deployment/webapp",
+					MatchLabels: map[string]string{"app": "webapp"},
+					MatchExpressions: []profile.LabelRequirement{
+						{Key: "track", Operator: "In", Values: []string{"canary"}},
+					},
+					FieldSelector: "status.phase=Running",
+				},
+			},
+			wide: true,
+			expected: []bubbletable.Row{
+				{"canary", "test_data/profile1.json", "busybox", "default", "deployment/webapp; app=webapp,track in (canary); status.phase=Running"},
 			},
 		},
 	}